@@ -0,0 +1,341 @@
+package filecache
+
+import (
+	"errors"
+	"github.com/spf13/afero"
+	"github.com/sunwei/hugo-playground/config"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestConfig(caches map[string]any) config.Provider {
+	cfg := config.New()
+	cfg.Set("workingDir", "/my/project")
+	cfg.Set("cacheDir", "/my/cache")
+	if caches != nil {
+		cfg.Set("caches", caches)
+	}
+	return cfg
+}
+
+func TestDecodeConfig(t *testing.T) {
+	cfg := newTestConfig(map[string]any{
+		"getresource": map[string]any{
+			"maxAge": "10s",
+			"dir":    ":cacheDir/remote",
+		},
+		"images": map[string]any{
+			"dir": ":resourceDir/_gen",
+		},
+	})
+
+	c, err := DecodeConfig(afero.NewMemMapFs(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c) != 4 {
+		t.Fatalf("got %d caches, want 4", len(c))
+	}
+
+	for _, test := range []struct {
+		name          string
+		dir           string
+		maxAge        time.Duration
+		isResourceDir bool
+	}{
+		{cacheKeyGetResource, filepath.FromSlash("/my/cache/remote/filecache/getresource"), 10 * time.Second, false},
+		{cacheKeyImages, filepath.FromSlash("_gen/images"), -1, true},
+		{cacheKeyAssets, filepath.FromSlash("_gen/assets"), -1, true},
+		{cacheKeyModules, filepath.FromSlash("/my/cache/modules/filecache/modules"), -1, false},
+	} {
+		cc := c[test.name]
+		if cc.Dir != test.dir {
+			t.Errorf("%s: got dir %q, want %q", test.name, cc.Dir, test.dir)
+		}
+		if cc.MaxAge != test.maxAge {
+			t.Errorf("%s: got maxAge %s, want %s", test.name, cc.MaxAge, test.maxAge)
+		}
+		if cc.isResourceDir != test.isResourceDir {
+			t.Errorf("%s: got isResourceDir %t, want %t", test.name, cc.isResourceDir, test.isResourceDir)
+		}
+	}
+}
+
+func TestDecodeConfigIgnoreCache(t *testing.T) {
+	cfg := newTestConfig(nil)
+	cfg.Set("ignoreCache", true)
+
+	c, err := DecodeConfig(afero.NewMemMapFs(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, cc := range c {
+		if cc.MaxAge != 0 {
+			t.Errorf("%s: got maxAge %s, want 0", k, cc.MaxAge)
+		}
+	}
+}
+
+func TestDecodeConfigInvalid(t *testing.T) {
+	for _, test := range []struct {
+		caches map[string]any
+		expect string
+	}{
+		{map[string]any{"nope": map[string]any{"dir": ":cacheDir"}}, `"nope" is not a valid cache name`},
+		{map[string]any{"images": map[string]any{"dir": ":nope/images"}}, `":nope" is not a valid placeholder`},
+		{map[string]any{"images": map[string]any{"dir": "/"}}, "is a root folder and not allowed as cache dir"},
+	} {
+		_, err := DecodeConfig(afero.NewMemMapFs(), newTestConfig(test.caches))
+		if err == nil || !strings.Contains(err.Error(), test.expect) {
+			t.Errorf("got error %v, want %q", err, test.expect)
+		}
+	}
+}
+
+func TestGetOrCreate(t *testing.T) {
+	var calls int
+	create := func() (io.ReadCloser, error) {
+		calls++
+		return ioutil.NopCloser(strings.NewReader("content")), nil
+	}
+
+	for _, test := range []struct {
+		maxAge time.Duration
+		calls  int
+	}{
+		{-1, 1},
+		{time.Hour, 1},
+		{0, 3},
+	} {
+		calls = 0
+		c := NewCache(afero.NewMemMapFs(), test.maxAge)
+
+		for i := 0; i < 3; i++ {
+			info, r, err := c.GetOrCreate("a/b.txt", create)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Name != filepath.FromSlash("a/b.txt") {
+				t.Errorf("got name %q", info.Name)
+			}
+			b, _ := ioutil.ReadAll(r)
+			r.Close()
+			if string(b) != "content" {
+				t.Errorf("maxAge %s: got %q", test.maxAge, b)
+			}
+		}
+
+		if calls != test.calls {
+			t.Errorf("maxAge %s: create called %d times, want %d", test.maxAge, calls, test.calls)
+		}
+
+		_, b, err := c.GetBytes("a/b.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.maxAge == 0 {
+			if b != nil {
+				t.Errorf("maxAge 0: got %q from disabled cache", b)
+			}
+		} else if string(b) != "content" {
+			t.Errorf("maxAge %s: got %q", test.maxAge, b)
+		}
+	}
+}
+
+func TestGetOrCreateExpired(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := NewCache(fs, time.Hour)
+
+	if _, _, err := c.GetOrCreate("a.txt", func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("v1")), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	age(t, fs, "a.txt", 2*time.Hour)
+
+	_, r, err := c.Get("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != nil {
+		t.Fatal("expected expired item to be removed")
+	}
+	if exists, _ := afero.Exists(fs, "a.txt"); exists {
+		t.Fatal("expected expired file to be removed")
+	}
+}
+
+func TestGetOrCreateOrStale(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := NewCache(fs, time.Hour)
+
+	errOffline := errors.New("offline")
+	fail := func() (io.ReadCloser, error) {
+		return nil, errOffline
+	}
+
+	if _, _, err := c.GetOrCreateOrStale("a.txt", fail); err != errOffline {
+		t.Fatalf("got error %v, want %v", err, errOffline)
+	}
+
+	info, r, err := c.GetOrCreateOrStale("a.txt", func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("v1")), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, r, "v1")
+	if info.Stale {
+		t.Error("fresh item marked as stale")
+	}
+
+	// Not expired, so create is not invoked.
+	info, r, err = c.GetOrCreateOrStale("a.txt", fail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, r, "v1")
+	if info.Stale {
+		t.Error("cached item marked as stale")
+	}
+
+	age(t, fs, "a.txt", 2*time.Hour)
+
+	info, r, err = c.GetOrCreateOrStale("a.txt", fail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, r, "v1")
+	if !info.Stale {
+		t.Error("expected expired item to be marked as stale")
+	}
+
+	// Stale items are kept when pruning.
+	if _, err := c.Prune(false); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := afero.Exists(fs, "a.txt"); !exists {
+		t.Fatal("stale item was pruned")
+	}
+
+	info, r, err = c.GetOrCreateOrStale("a.txt", func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("v2")), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, r, "v2")
+	_, b, _ := c.GetBytes("a.txt")
+	if string(b) != "v2" {
+		t.Errorf("got %q, want refreshed item", b)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := NewCache(fs, time.Hour)
+
+	for _, id := range []string{"a.txt", "b.txt", "c/d.txt"} {
+		if err := afero.WriteFile(fs, id, []byte(id), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	age(t, fs, "a.txt", 2*time.Hour)
+
+	// Nothing tracked yet, so only the expired item goes.
+	count, err := c.Prune(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d pruned, want 1", count)
+	}
+
+	// Once the cache is in use, untouched items are removed.
+	if _, _, err := c.GetBytes("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	count, err = c.Prune(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d pruned, want 1", count)
+	}
+	if exists, _ := afero.Exists(fs, "b.txt"); !exists {
+		t.Fatal("used item was pruned")
+	}
+	if exists, _ := afero.Exists(fs, "c/d.txt"); exists {
+		t.Fatal("unused item was not pruned")
+	}
+
+	count, err = c.Prune(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d pruned, want 1", count)
+	}
+	// Directories left empty by the previous run are removed.
+	if exists, _ := afero.DirExists(fs, "c"); exists {
+		t.Fatal("expected empty dir to be removed")
+	}
+}
+
+func TestCachesPrune(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	caches := Caches{
+		"images": NewCache(afero.NewBasePathFs(fs, "images"), -1),
+		"assets": NewCache(afero.NewBasePathFs(fs, "assets"), time.Hour),
+	}
+
+	for _, name := range []string{"images/a.jpg", "assets/a.css"} {
+		if err := afero.WriteFile(fs, name, []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+		age(t, fs, name, 2*time.Hour)
+	}
+
+	if caches.Get("Images") != caches.ImageCache() {
+		t.Fatal("expected case insensitive cache lookup")
+	}
+
+	count, err := caches.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d pruned, want 1", count)
+	}
+	if exists, _ := afero.Exists(fs, "images/a.jpg"); !exists {
+		t.Fatal("item in a cache that never expires was pruned")
+	}
+}
+
+func age(t *testing.T, fs afero.Fs, name string, d time.Duration) {
+	t.Helper()
+	old := time.Now().Add(-d)
+	if err := fs.Chtimes(name, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertContent(t *testing.T, r io.ReadCloser, expect string) {
+	t.Helper()
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != expect {
+		t.Fatalf("got %q, want %q", b, expect)
+	}
+}
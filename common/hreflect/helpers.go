@@ -1,6 +1,7 @@
 package hreflect
 
 import (
+	"context"
 	"github.com/sunwei/hugo-playground/common/htime"
 	"github.com/sunwei/hugo-playground/common/types"
 	"reflect"
@@ -173,3 +174,5 @@ func IsInt(kind reflect.Kind) bool {
 		return false
 	}
 }
+
+var ContextInterface = reflect.TypeOf((*context.Context)(nil)).Elem()
@@ -23,6 +23,11 @@ type ReadSeekCloserProvider interface {
 	ReadSeekCloser() (ReadSeekCloser, error)
 }
 
+// NewReadSeekerNoOpCloser creates a new ReadSeekerNoOpCloser with the given ReadSeeker.
+func NewReadSeekerNoOpCloser(r ReadSeeker) ReadSeekerNoOpCloser {
+	return ReadSeekerNoOpCloser{r}
+}
+
 // NewReadSeekerNoOpCloserFromString uses strings.NewReader to create a new ReadSeekerNoOpCloser
 // from the given string.
 func NewReadSeekerNoOpCloserFromString(content string) ReadSeekerNoOpCloser {
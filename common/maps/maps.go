@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/spf13/cast"
 	"github.com/sunwei/hugo-playground/types"
+	"strings"
 )
 
 // ToParamsAndPrepare converts in to Params and prepares it for use.
@@ -91,3 +92,17 @@ func ToSliceStringMap(in any) ([]map[string]any, error) {
 		return nil, fmt.Errorf("unable to cast %#v of type %T to []map[string]interface{}", in, in)
 	}
 }
+
+// LookupEqualFold finds key in m with case insensitive equality checks.
+func LookupEqualFold[T any | string](m map[string]T, key string) (T, bool) {
+	if v, found := m[key]; found {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	var s T
+	return s, false
+}
@@ -46,6 +46,17 @@ func (filepathBridge) Separator() string {
 
 var fpb filepathBridge
 
+// PathAndExt is the same as FileAndExt, but it uses the path package.
+func PathAndExt(in string) (string, string) {
+	return fileAndExt(in, pb)
+}
+
+// FileAndExt takes a path and returns the file and extension separated,
+// the extension including the delimiter, i.e. ".md".
+func FileAndExt(in string) (string, string) {
+	return fileAndExt(in, fpb)
+}
+
 // Filename takes a file path, strips out the extension,
 // and returns the name of the file.
 func Filename(in string) (name string) {
@@ -1,6 +1,36 @@
 package types
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cast"
+)
+
+// KeyValues holds an key and a slice of values.
+type KeyValues struct {
+	Key    any
+	Values []any
+}
+
+// KeyString returns the key as a string, an empty string if conversion fails.
+func (k KeyValues) KeyString() string {
+	return cast.ToString(k.Key)
+}
+
+func (k KeyValues) String() string {
+	return fmt.Sprintf("%v: %v", k.Key, k.Values)
+}
+
+// NewKeyValuesStrings takes a given key and slice of values and returns a new
+// KeyValues struct.
+func NewKeyValuesStrings(key string, values ...string) KeyValues {
+	iv := make([]any, len(values))
+	for i := 0; i < len(values); i++ {
+		iv[i] = values[i]
+	}
+	return KeyValues{Key: key, Values: iv}
+}
 
 // IsNil reports whether v is nil.
 func IsNil(v any) bool {
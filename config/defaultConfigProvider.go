@@ -54,11 +54,13 @@ func (c *defaultConfigProvider) Get(k string) any {
 }
 
 func (c *defaultConfigProvider) GetBool(k string) bool {
-	return false
+	v := c.Get(k)
+	return cast.ToBool(v)
 }
 
 func (c *defaultConfigProvider) GetInt(k string) int {
-	return 0
+	v := c.Get(k)
+	return cast.ToInt(v)
 }
 
 func (c *defaultConfigProvider) IsSet(k string) bool {
@@ -86,7 +88,8 @@ func (c *defaultConfigProvider) GetParams(k string) maps.Params {
 }
 
 func (c *defaultConfigProvider) GetStringMap(k string) map[string]any {
-	return nil
+	v := c.Get(k)
+	return maps.ToStringMap(v)
 }
 
 func (c *defaultConfigProvider) GetStringMapString(k string) map[string]string {
@@ -95,7 +98,8 @@ func (c *defaultConfigProvider) GetStringMapString(k string) map[string]string {
 }
 
 func (c *defaultConfigProvider) GetStringSlice(k string) []string {
-	return nil
+	v := c.Get(k)
+	return cast.ToStringSlice(v)
 }
 
 func (c *defaultConfigProvider) Set(k string, v any) {
@@ -2,6 +2,8 @@ package deps
 
 import (
 	"fmt"
+	"github.com/spf13/cast"
+	"github.com/sunwei/hugo-playground/cache/filecache"
 	"github.com/sunwei/hugo-playground/common/loggers"
 	"github.com/sunwei/hugo-playground/config"
 	"github.com/sunwei/hugo-playground/helpers"
@@ -9,11 +11,13 @@ import (
 	"github.com/sunwei/hugo-playground/langs"
 	"github.com/sunwei/hugo-playground/log"
 	"github.com/sunwei/hugo-playground/media"
+	"github.com/sunwei/hugo-playground/metrics"
 	"github.com/sunwei/hugo-playground/output"
 	"github.com/sunwei/hugo-playground/resources"
 	"github.com/sunwei/hugo-playground/resources/page"
 	"github.com/sunwei/hugo-playground/source"
 	"github.com/sunwei/hugo-playground/tpl"
+	"sync"
 )
 
 // Deps holds dependencies used by many.
@@ -38,6 +42,9 @@ type Deps struct {
 	// The Resource Spec to use
 	ResourceSpec *resources.Spec
 
+	// The configured file caches.
+	FileCaches filecache.Caches
+
 	// The SourceSpec to use
 	SourceSpec *source.SourceSpec `json:"-"`
 
@@ -60,12 +67,48 @@ type Deps struct {
 
 	// The translation func to use
 	Translate func(translationID string, templateData any) string `json:"-"`
+
+	// BuildStartListeners will be notified before a build starts.
+	BuildStartListeners *Listeners
+
+	// Template execution metrics, set when templateMetrics is enabled.
+	Metrics metrics.Provider
+}
+
+// Listeners represents an event listener.
+type Listeners struct {
+	sync.Mutex
+
+	// A list of funcs to be notified about an event.
+	listeners []func()
+}
+
+// Add adds a function to a Listeners instance.
+func (b *Listeners) Add(f func()) {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.listeners = append(b.listeners, f)
+}
+
+// Notify executes all listener functions.
+func (b *Listeners) Notify() {
+	b.Lock()
+	defer b.Unlock()
+	for _, notify := range b.listeners {
+		notify()
+	}
 }
 
 // DepsCfg contains configuration options that can be used to configure Hugo
 // on a global level, i.e. logging etc.
 // Nil values will be given default values.
 type DepsCfg struct {
+	// The Logger to use.
+	Logger loggers.Logger
+
 	// The language to use.
 	Language *langs.Language
 
@@ -84,6 +127,10 @@ type DepsCfg struct {
 	// The output formats configured.
 	OutputFormats output.Formats
 
+	// The file caches to use, shared by all sites.
+	// Nil means that they will be created from the configuration.
+	FileCaches filecache.Caches
+
 	// Template handling.
 	TemplateProvider ResourceProvider
 }
@@ -122,6 +169,11 @@ func New(cfg DepsCfg) (*Deps, error) {
 		panic("Must get fs ready: deps.New")
 	}
 
+	logger := cfg.Logger
+	if logger == nil {
+		logger = loggers.NewErrorLogger()
+	}
+
 	if cfg.MediaTypes == nil {
 		cfg.MediaTypes = media.DefaultTypes
 	}
@@ -136,8 +188,16 @@ func New(cfg DepsCfg) (*Deps, error) {
 		return nil, fmt.Errorf("create PathSpec: %w", err)
 	}
 
-	log.Process("New resources Spec", "with pathSpec, outputFormats, MediaTypes")
-	resourceSpec, err := resources.NewSpec(ps, cfg.OutputFormats, cfg.MediaTypes)
+	fileCaches := cfg.FileCaches
+	if fileCaches == nil {
+		fileCaches, err = filecache.NewCaches(ps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file caches from configuration: %w", err)
+		}
+	}
+
+	log.Process("New resources Spec", "with pathSpec, fileCaches, outputFormats, MediaTypes")
+	resourceSpec, err := resources.NewSpec(ps, fileCaches, logger, cfg.OutputFormats, cfg.MediaTypes)
 	if err != nil {
 		return nil, err
 	}
@@ -151,16 +211,26 @@ func New(cfg DepsCfg) (*Deps, error) {
 	log.Process("New source Spec", "with source filesystem and language")
 	sp := source.NewSourceSpec(ps, nil, fs.Source)
 
+	ignoreErrors := cast.ToStringSlice(cfg.Cfg.Get("ignoreErrors"))
+	ignorableLogger := loggers.NewIgnorableLogger(logger, ignoreErrors...)
+
 	d := &Deps{
-		Fs:               fs,
-		templateProvider: cfg.TemplateProvider,
-		PathSpec:         ps,
-		ContentSpec:      contentSpec,
-		SourceSpec:       sp,
-		ResourceSpec:     resourceSpec,
-		Cfg:              cfg.Language,
-		Language:         cfg.Language,
-		Site:             cfg.Site,
+		Log:                 ignorableLogger,
+		Fs:                  fs,
+		templateProvider:    cfg.TemplateProvider,
+		PathSpec:            ps,
+		ContentSpec:         contentSpec,
+		SourceSpec:          sp,
+		ResourceSpec:        resourceSpec,
+		FileCaches:          fileCaches,
+		Cfg:                 cfg.Language,
+		Language:            cfg.Language,
+		Site:                cfg.Site,
+		BuildStartListeners: &Listeners{},
+	}
+
+	if cfg.Cfg.GetBool("templateMetrics") {
+		d.Metrics = metrics.NewProvider(cfg.Cfg.GetBool("templateMetricsHints"))
 	}
 
 	return d, nil
@@ -18,24 +18,38 @@ require (
 )
 
 require (
+	github.com/PuerkitoBio/purell v1.1.1
 	github.com/alecthomas/chroma/v2 v2.2.0
 	github.com/bep/clocks v0.5.0
 	github.com/bep/gitmap v1.3.0
 	github.com/bep/goat v0.5.0
+	github.com/bep/tmc v0.5.1
 	github.com/clbanning/mxj/v2 v2.5.6
+	github.com/disintegration/gift v1.2.1
+	github.com/evanw/esbuild v0.17.19
+	github.com/gobuffalo/flect v0.3.0
+	github.com/gohugoio/locales v0.14.0
+	github.com/gohugoio/localescompressed v1.0.1
 	github.com/kyokomi/emoji/v2 v2.2.10
+	github.com/marekm4/color-extractor v1.2.0
 	github.com/mattn/go-isatty v0.0.16
 	github.com/mitchellh/hashstructure v1.1.0
+	github.com/muesli/smartcrop v0.3.0
+	github.com/rwcarlsen/goexif v0.0.0-20190401172101-9e8deecbddbd
 	github.com/spf13/jwalterweatherman v1.1.0
 	github.com/tdewolff/minify/v2 v2.12.1
 	github.com/yuin/goldmark v1.4.13
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	golang.org/x/text v0.3.7
 	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
+	github.com/PuerkitoBio/urlesc v0.0.0-20170810143723-de5bf2ad4578 // indirect
 	github.com/dlclark/regexp2 v1.4.0 // indirect
+	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646 // indirect
 	github.com/tdewolff/parse/v2 v2.6.3 // indirect
+	golang.org/x/net v0.0.0-20211015210444-4f30a5c0130f // indirect
 	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
 )
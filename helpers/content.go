@@ -29,13 +29,30 @@ func NewContentSpec(cfg config.Provider, contentFs afero.Fs) (*ContentSpec, erro
 	}
 
 	spec.Converters = converterProvider
+	p := converterProvider.Get("markdown")
+	conv, err := p.New(converter.DocumentContext{})
+	if err != nil {
+		return nil, err
+	}
+	if as, ok := conv.(converter.AnchorNameSanitizer); ok {
+		spec.anchorNameSanitizer = as
+	} else {
+		// Use Goldmark's sanitizer
+		p := converterProvider.Get("goldmark")
+		conv, err := p.New(converter.DocumentContext{})
+		if err != nil {
+			return nil, err
+		}
+		spec.anchorNameSanitizer = conv.(converter.AnchorNameSanitizer)
+	}
 
 	return spec, nil
 }
 
 // ContentSpec provides functionality to render markdown content.
 type ContentSpec struct {
-	Converters markup.ConverterProvider
+	Converters          markup.ConverterProvider
+	anchorNameSanitizer converter.AnchorNameSanitizer
 
 	// SummaryLength is the length of the summary that Hugo extracts from a content.
 	summaryLength int
@@ -43,6 +60,12 @@ type ContentSpec struct {
 	Cfg config.Provider
 }
 
+// SanitizeAnchorName sanitizes s into an anchor name the same way the
+// configured markdown renderer creates heading IDs.
+func (c *ContentSpec) SanitizeAnchorName(s string) string {
+	return c.anchorNameSanitizer.SanitizeAnchorName(s)
+}
+
 func (c *ContentSpec) ResolveMarkup(in string) string {
 	in = strings.ToLower(in)
 	switch in {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/spf13/afero"
 	"github.com/sunwei/hugo-playground/common/hugio"
+	"github.com/sunwei/hugo-playground/common/text"
 	"github.com/sunwei/hugo-playground/config"
 	"github.com/sunwei/hugo-playground/hugofs"
 	"io"
@@ -129,6 +130,9 @@ func (p *PathSpec) MakePath(s string) string {
 // Hyphens in the original input are maintained.
 // Spaces will be replaced with a single hyphen, and sequential replacement hyphens will be reduced to one.
 func (p *PathSpec) UnicodeSanitize(s string) string {
+	if p.RemovePathAccents {
+		s = text.RemoveAccentsString(s)
+	}
 
 	source := []rune(s)
 	target := make([]rune, 0, len(source))
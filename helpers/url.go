@@ -1,12 +1,56 @@
 package helpers
 
 import (
+	"github.com/PuerkitoBio/purell"
+	"github.com/sunwei/hugo-playground/common/paths"
 	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
 )
 
+func sanitizeURLWithFlags(in string, f purell.NormalizationFlags) string {
+	s, err := purell.NormalizeURLString(in, f)
+	if err != nil {
+		return in
+	}
+
+	// Temporary workaround for the bug fix and resulting
+	// behavioral change in purell.NormalizeURLString():
+	// a leading '/' was inadvertently added to relative links,
+	// but no longer, see #878.
+	//
+	// I think the real solution is to allow Hugo to
+	// make relative URL with relative path,
+	// e.g. "../../post/hello-again/", as wished by users
+	// in issues #157, #622, etc., without forcing
+	// relative URLs to begin with '/'.
+	// Once the fixes are in, let's remove this kludge
+	// and restore SanitizeURL() to the way it was.
+	//                         -- @anthonyfok, 2015-02-16
+	//
+	// Begin temporary kludge
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	if len(u.Path) > 0 && !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+	// End temporary kludge
+}
+
+// SanitizeURL sanitizes the input URL string.
+func SanitizeURL(in string) string {
+	return sanitizeURLWithFlags(in, purell.FlagsSafe|purell.FlagRemoveTrailingSlash|purell.FlagRemoveDotSegments|purell.FlagRemoveDuplicateSlashes|purell.FlagRemoveUnnecessaryHostDots|purell.FlagRemoveEmptyPortSeparator)
+}
+
+// SanitizeURLKeepTrailingSlash is the same as SanitizeURL, but will keep any trailing slash.
+func SanitizeURLKeepTrailingSlash(in string) string {
+	return sanitizeURLWithFlags(in, purell.FlagsSafe|purell.FlagRemoveDotSegments|purell.FlagRemoveDuplicateSlashes|purell.FlagRemoveUnnecessaryHostDots|purell.FlagRemoveEmptyPortSeparator)
+}
+
 // URLize is similar to MakePath, but with Unicode handling
 // Example:
 //     uri: Vim (text editor)
@@ -17,6 +61,9 @@ func (p *PathSpec) URLize(uri string) string {
 
 // MakePathSanitized creates a Unicode-sanitized string, with the spaces replaced
 func (p *PathSpec) MakePathSanitized(s string) string {
+	if p.DisablePathToLower {
+		return p.MakePath(s)
+	}
 	return strings.ToLower(p.MakePath(s))
 }
 
@@ -38,6 +85,116 @@ func (p *PathSpec) URLizeFilename(filename string) string {
 	return p.URLEscape(filepath.ToSlash(filename))
 }
 
+// AbsURL creates an absolute URL from the relative path given and the BaseURL set in config.
+func (p *PathSpec) AbsURL(in string, addLanguage bool) string {
+	url, err := url.Parse(in)
+	if err != nil {
+		return in
+	}
+
+	if url.IsAbs() || strings.HasPrefix(in, "//") {
+		// It  is already  absolute, return it as is.
+		return in
+	}
+
+	baseURL := p.getBaseURLRoot(in)
+
+	if addLanguage {
+		prefix := p.GetLanguagePrefix()
+		if prefix != "" {
+			hasPrefix := false
+			// avoid adding language prefix if already present
+			in2 := in
+			if strings.HasPrefix(in, "/") {
+				in2 = in[1:]
+			}
+			if in2 == prefix {
+				hasPrefix = true
+			} else {
+				hasPrefix = strings.HasPrefix(in2, prefix+"/")
+			}
+
+			if !hasPrefix {
+				addSlash := in == "" || strings.HasSuffix(in, "/")
+				in = path.Join(prefix, in)
+
+				if addSlash {
+					in += "/"
+				}
+			}
+		}
+	}
+
+	return paths.MakePermalink(baseURL, in).String()
+}
+
+func (p *PathSpec) getBaseURLRoot(path string) string {
+	if strings.HasPrefix(path, "/") {
+		// Treat it as relative to the server root.
+		return p.BaseURLNoPathString
+	} else {
+		// Treat it as relative to the baseURL.
+		return p.BaseURLString
+	}
+}
+
+// RelURL creates a URL relative to the BaseURL root.
+// Note: The result URL will not include the context root if canonifyURLs is enabled.
+func (p *PathSpec) RelURL(in string, addLanguage bool) string {
+	baseURL := p.getBaseURLRoot(in)
+	canonifyURLs := p.CanonifyURLs
+	if (!strings.HasPrefix(in, baseURL) && strings.HasPrefix(in, "http")) || strings.HasPrefix(in, "//") {
+		return in
+	}
+
+	u := in
+
+	if strings.HasPrefix(in, baseURL) {
+		u = strings.TrimPrefix(u, baseURL)
+	}
+
+	if addLanguage {
+		prefix := p.GetLanguagePrefix()
+		if prefix != "" {
+			hasPrefix := false
+			// avoid adding language prefix if already present
+			in2 := in
+			if strings.HasPrefix(in, "/") {
+				in2 = in[1:]
+			}
+			if in2 == prefix {
+				hasPrefix = true
+			} else {
+				hasPrefix = strings.HasPrefix(in2, prefix+"/")
+			}
+
+			if !hasPrefix {
+				hadSlash := strings.HasSuffix(u, "/")
+
+				u = path.Join(prefix, u)
+
+				if hadSlash {
+					u += "/"
+				}
+			}
+		}
+	}
+
+	if !canonifyURLs {
+		u = paths.AddContextRoot(baseURL, u)
+	}
+
+	if in == "" && !strings.HasSuffix(u, "/") && strings.HasSuffix(baseURL, "/") {
+		u += "/"
+	}
+
+	if !strings.HasPrefix(u, "/") {
+		u = "/" + u
+	}
+
+	return u
+}
+
 // PrependBasePath prepends any baseURL sub-folder to the given resource
 func (p *PathSpec) PrependBasePath(rel string, isAbs bool) string {
 	basePath := p.GetBasePath(!isAbs)
@@ -44,8 +44,6 @@ var (
 		"pandoc", "pdc",
 	}
 
-	contentFileExtensionsSet map[string]bool
-
 	htmlFileExtensions = []string{
 		"html", "htm",
 	}
@@ -54,38 +52,41 @@ var (
 )
 
 func init() {
-	contentFileExtensionsSet = make(map[string]bool)
-	for _, ext := range contentFileExtensions {
-		contentFileExtensionsSet[ext] = true
-	}
 	htmlFileExtensionsSet = make(map[string]bool)
 	for _, ext := range htmlFileExtensions {
 		htmlFileExtensionsSet[ext] = true
 	}
 }
 
-func IsContentFile(filename string) bool {
-	return contentFileExtensionsSet[strings.TrimPrefix(filepath.Ext(filename), ".")]
-}
+// ContentFileExtensions is a set of extensions, without the leading ".",
+// of the files that are treated as content.
+type ContentFileExtensions map[string]bool
 
-func IsIndexContentFile(filename string) bool {
-	if !IsContentFile(filename) {
-		return false
+// NewContentFileExtensions creates a set with the built-in content file
+// extensions and the given extra ones, e.g. "mdx" for "post.mdx".
+func NewContentFileExtensions(extra ...string) ContentFileExtensions {
+	set := make(ContentFileExtensions)
+	for _, ext := range contentFileExtensions {
+		set[ext] = true
 	}
+	for _, ext := range extra {
+		set[strings.TrimPrefix(ext, ".")] = true
+	}
+	return set
+}
 
-	base := filepath.Base(filename)
+func (c ContentFileExtensions) IsContentFile(filename string) bool {
+	return c[strings.TrimPrefix(filepath.Ext(filename), ".")]
+}
 
-	return strings.HasPrefix(base, "index.") || strings.HasPrefix(base, "_index.")
+func (c ContentFileExtensions) IsContentExt(ext string) bool {
+	return c[ext]
 }
 
 func IsHTMLFile(filename string) bool {
 	return htmlFileExtensionsSet[strings.TrimPrefix(filepath.Ext(filename), ".")]
 }
 
-func IsContentExt(ext string) bool {
-	return contentFileExtensionsSet[ext]
-}
-
 type ContentClass string
 
 const (
@@ -99,8 +100,10 @@ func (c ContentClass) IsBundle() bool {
 	return c == ContentClassLeaf || c == ContentClassBranch
 }
 
-func ClassifyContentFile(filename string, open func() (afero.File, error)) ContentClass {
-	if !IsContentFile(filename) {
+// ClassifyContentFile classifies filename as a leaf or branch bundle, a content
+// file or a regular file, using the content file extensions in c.
+func (c ContentFileExtensions) ClassifyContentFile(filename string, open func() (afero.File, error)) ContentClass {
+	if !c.IsContentFile(filename) {
 		return ContentClassFile
 	}
 
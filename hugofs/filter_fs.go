@@ -33,7 +33,7 @@ var (
 	_ afero.File    = (*filterDir)(nil)
 )
 
-func NewLanguageFs(langs map[string]int, fs afero.Fs) (afero.Fs, error) {
+func NewLanguageFs(langs map[string]int, contentExts files.ContentFileExtensions, fs afero.Fs) (afero.Fs, error) {
 	applyMeta := func(fs *FilterFs, name string, fis []os.FileInfo) {
 		for i, fi := range fis {
 			if fi.IsDir() {
@@ -63,7 +63,7 @@ func NewLanguageFs(langs map[string]int, fs afero.Fs) (afero.Fs, error) {
 					Weight:                     weight,
 					TranslationBaseName:        translationBaseName,
 					TranslationBaseNameWithExt: translationBaseNameWithExt,
-					Classifier:                 files.ClassifyContentFile(fi.Name(), meta.OpenFunc),
+					Classifier:                 contentExts.ClassifyContentFile(fi.Name(), meta.OpenFunc),
 				})
 
 			fis[i] = fim
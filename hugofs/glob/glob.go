@@ -122,7 +122,11 @@ func GetGlob(pattern string) (glob.Glob, error) {
 }
 
 func NormalizePath(p string) string {
-	return strings.Trim(path.Clean(filepath.ToSlash(strings.ToLower(p))), "/.")
+	return strings.ToLower(NormalizePathNoLower(p))
+}
+
+func NormalizePathNoLower(p string) string {
+	return strings.Trim(path.Clean(filepath.ToSlash(p)), "/.")
 }
 
 // ResolveRootDir takes a normalized path on the form "assets/**.json" and
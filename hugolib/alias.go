@@ -0,0 +1,34 @@
+package hugolib
+
+import (
+	bp "github.com/sunwei/hugo-playground/bufferpool"
+	"github.com/sunwei/hugo-playground/output"
+	"github.com/sunwei/hugo-playground/publisher"
+	"html/template"
+)
+
+const alias = "<!DOCTYPE html><html><head><title>{{ .Permalink }}</title><link rel=\"canonical\" href=\"{{ .Permalink }}\"><meta name=\"robots\" content=\"noindex\"><meta charset=\"utf-8\"><meta http-equiv=\"refresh\" content=\"0; url={{ .Permalink }}\"></head></html>"
+
+var defaultAliasTemplate = template.Must(template.New("alias").Parse(alias))
+
+type aliasPage struct {
+	Permalink string
+}
+
+// publishDestAlias writes a page to path that redirects to permalink.
+func (s *Site) publishDestAlias(path, permalink string) error {
+	buf := bp.GetBuffer()
+	defer bp.PutBuffer(buf)
+
+	if err := defaultAliasTemplate.Execute(buf, aliasPage{Permalink: permalink}); err != nil {
+		return err
+	}
+
+	pd := publisher.Descriptor{
+		Src:          buf,
+		TargetPath:   s.PathSpec.MakePath(path + "/index.html"),
+		OutputFormat: output.HTMLFormat,
+	}
+
+	return s.publisher.Publish(pd)
+}
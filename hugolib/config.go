@@ -70,7 +70,7 @@ func (l configLoader) applyConfigDefaults() error {
 		"removePathAccents":                    false,
 		"titleCaseStyle":                       "AP",
 		"taxonomies":                           maps.Params{"tag": "tags", "category": "categories"},
-		"permalinks":                           maps.Params{"a": "b"},
+		"permalinks":                           maps.Params{},
 		"sitemap":                              maps.Params{"priority": -1, "filename": "sitemap.xml"},
 		"disableLiveReload":                    false,
 		"pluralizeListTitles":                  true,
@@ -93,6 +93,8 @@ func (l configLoader) applyConfigDefaults() error {
 		"disableAliases":                       false,
 		"debug":                                false,
 		"disableFastRender":                    false,
+		"refLinksErrorLevel":                   "ERROR",
+		"refLinksNotFoundURL":                  "",
 		"timeout":                              "30s",
 		"enableInlineShortcodes":               false,
 	}
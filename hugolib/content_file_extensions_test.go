@@ -0,0 +1,37 @@
+package hugolib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentFileExtensions(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+CONFIG
+-- content/a.md --
+---
+title: "A"
+---
+-- content/b.notes --
+---
+title: "B"
+markup: "markdown"
+---
+*notes*
+-- layouts/index.html --
+HOME{{ range .Site.RegularPages }}|{{ .Title }}{{ end }}
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .Content }}
+`
+
+	b := Test(t, strings.Replace(files, "CONFIG", `contentFileExtensions = [".notes"]`, 1))
+	b.AssertFileContent("index.html", "HOME|A|B")
+	b.AssertFileContent("b/index.html", "SINGLE B|<p><em>notes</em></p>")
+
+	// The setting is per configuration; b.notes is a plain file here.
+	b = Test(t, strings.Replace(files, "CONFIG", "", 1))
+	b.AssertFileContent("index.html", "HOME|A")
+	b.AssertFileExists("b/index.html", false)
+}
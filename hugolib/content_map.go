@@ -10,6 +10,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 type contentTree struct {
@@ -36,6 +37,10 @@ type contentMap struct {
 
 	// Resources stored per bundle below a common prefix, e.g. "/blog/post__hb_".
 	resources *contentTree
+
+	// The index used by GetPage and ref/relRef, built from the page and
+	// section trees on first use after they are assembled.
+	pageIndex *contentTreeLookupIndex
 }
 
 // contentTreeRef points to a node in the given tree.
@@ -146,9 +151,100 @@ func newContentMap() *contentMap {
 		m.pages, m.sections,
 	}
 
+	m.pageIndex = &contentTreeLookupIndex{
+		t: []*contentTree{m.pages, m.sections},
+	}
+	m.pageIndex.Reset()
+
 	return m
 }
 
+// Used to mark ambiguous keys in reverse index lookups.
+var ambiguousContentNode = &contentNode{}
+
+// contentTreeLookupIndex maps the logical paths of pages and sections, e.g.
+// "/blog/post" and "/blog", to their nodes. It also holds a reverse index
+// used as a fallback in GetPage. There are currently two cases where this is
+// used:
+// 1. Short name lookups in ref/relRef, e.g. using only "mypage.md" without a path.
+// 2. Links resolved from a remounted content directory. These are restricted to the same module.
+// Both of the above cases can result in ambigous lookup errors.
+type contentTreeLookupIndex struct {
+	t []*contentTree
+	*contentTreeLookupIndexMaps
+}
+
+type contentTreeLookupIndexMaps struct {
+	init    sync.Once
+	paths   map[string]*contentNode
+	reverse map[string]*contentNode
+}
+
+// Reset discards the index, it will be rebuilt from the trees on the next lookup.
+func (c *contentTreeLookupIndex) Reset() {
+	c.contentTreeLookupIndexMaps = &contentTreeLookupIndexMaps{}
+}
+
+// GetPath returns the page or section with the given logical path, e.g.
+// "/blog/post" or "/blog/", or nil if not found.
+func (c *contentTreeLookupIndex) GetPath(p string) *contentNode {
+	c.build()
+	if p != "/" {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return c.paths[p]
+}
+
+// GetReverse returns the node with the given short name or module path,
+// ambiguousContentNode if there are more than one, or nil if not found.
+func (c *contentTreeLookupIndex) GetReverse(key string) *contentNode {
+	c.build()
+	return c.reverse[key]
+}
+
+func (c *contentTreeLookupIndex) build() {
+	c.init.Do(func() {
+		c.paths = make(map[string]*contentNode)
+		c.reverse = make(map[string]*contentNode)
+
+		addToReverseMap := func(k string, n *contentNode) {
+			k = strings.ToLower(k)
+			existing, found := c.reverse[k]
+			if found && existing != ambiguousContentNode {
+				c.reverse[k] = ambiguousContentNode
+			} else if !found {
+				c.reverse[k] = n
+			}
+		}
+
+		// Sections are added last, so they win over a page with the same path.
+		for _, tree := range c.t {
+			tree.Walk(func(s string, v any) bool {
+				n := v.(*contentNode)
+				if n.p != nil && !n.p.File().IsZero() {
+					meta := n.p.File().FileInfo().Meta()
+					if meta.Path != meta.PathFile() {
+						// Keep track of the original mount source.
+						mountKey := filepath.ToSlash(filepath.Join(meta.Module, meta.PathFile()))
+						addToReverseMap(mountKey, n)
+					}
+				}
+
+				// Page keys are on the form /blog/__hb_post__hl_, section keys /blog/.
+				p := strings.Replace(strings.TrimSuffix(s, cmLeafSeparator), cmBranchSeparator, "", 1)
+				if p != "/" {
+					p = strings.TrimSuffix(p, "/")
+				}
+				c.paths[p] = n
+
+				k := strings.TrimPrefix(strings.TrimSuffix(path.Base(s), cmLeafSeparator), cmBranchSeparator)
+				addToReverseMap(k, n)
+				return false
+			})
+		}
+	})
+}
+
 const (
 	cmBranchSeparator = "__hb_"
 	cmLeafSeparator   = "__hl_"
@@ -166,6 +262,8 @@ func (c contentTrees) Walk(fn contentTreeNodeCallback) {
 func (m *contentMap) AddFilesBundle(header hugofs.FileMetaInfo, resources ...hugofs.FileMetaInfo) error {
 	var (
 		meta       = header.Meta()
+		classifier = meta.Classifier
+		isBranch   = classifier == files.ContentClassBranch
 		bundlePath = m.getBundleDir(meta)
 
 		n = m.newContentNodeFromFi(header)
@@ -174,9 +272,20 @@ func (m *contentMap) AddFilesBundle(header hugofs.FileMetaInfo, resources ...hug
 		section string
 	)
 
-	// A regular page. Attach it to its section.
-	section, _ = m.getOrCreateSection(n, bundlePath) // /abc/
-	b = b.WithSection(section).ForPage(bundlePath).Insert(n)
+	if isBranch {
+		// A section with its own _index file.
+		section = bundlePath
+		b.WithSection(section).Insert(n)
+	} else {
+		// A regular page. Attach it to its section.
+		section, _ = m.getOrCreateSection(n, bundlePath) // /abc/
+		b = b.WithSection(section).ForPage(bundlePath).Insert(n)
+	}
+
+	for _, r := range resources {
+		rb := b.ForResource(cleanTreeKey(r.Meta().Path))
+		rb.Insert(&contentNode{fi: r})
+	}
 
 	return nil
 }
@@ -6,10 +6,14 @@ import (
 	"github.com/sunwei/hugo-playground/common/hugio"
 	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/common/para"
+	"github.com/sunwei/hugo-playground/hugofs"
+	"github.com/sunwei/hugo-playground/hugofs/files"
 	"github.com/sunwei/hugo-playground/log"
 	"github.com/sunwei/hugo-playground/parser/pageparser"
+	"github.com/sunwei/hugo-playground/resources"
 	"github.com/sunwei/hugo-playground/resources/page"
 	"github.com/sunwei/hugo-playground/resources/resource"
+	"path/filepath"
 	"strings"
 	"sync"
 )
@@ -103,6 +107,10 @@ func (m *pageMaps) AssemblePages() error {
 			return err
 		}
 
+		// The page trees are now complete, so make sure the GetPage
+		// index gets built from them on first lookup.
+		pm.pageIndex.Reset()
+
 		return nil
 	})
 }
@@ -223,7 +231,10 @@ func (m *pageMap) assembleSections() error {
 		}
 
 		if n.fi != nil {
-			panic("assembleSections newPageFromContentNode not ready")
+			n.p, err = m.newPageFromContentNode(n, parentBucket, nil)
+			if err != nil {
+				return true
+			}
 		} else { // new page
 			n.p = m.s.newPage(n, parentBucket, kind, "", sections...)
 		}
@@ -256,6 +267,19 @@ func (b *pagesMapBucket) getSections() page.Pages {
 	return b.sections
 }
 
+func (m *pageMap) collectPages(query pageMapQuery, fn func(c *contentNode)) error {
+	if query.Filter == nil {
+		query.Filter = contentTreeNoListAlwaysFilter
+	}
+
+	m.pages.WalkQuery(query, func(s string, n *contentNode) bool {
+		fn(n)
+		return false
+	})
+
+	return nil
+}
+
 func (m *pageMap) collectSections(query pageMapQuery, fn func(c *contentNode)) error {
 	level := strings.Count(query.Prefix, "/")
 
@@ -282,6 +306,13 @@ func (m *pageMap) collectSectionsFn(query pageMapQuery, fn func(s string, c *con
 	return nil
 }
 
+func (m *pageMap) collectSectionsRecursiveIncludingSelf(query pageMapQuery, fn func(c *contentNode)) error {
+	return m.collectSectionsFn(query, func(s string, c *contentNode) bool {
+		fn(c)
+		return false
+	})
+}
+
 type sectionAggregateHandler struct {
 	sectionAggregate
 	sectionPageCount int
@@ -510,8 +541,72 @@ func (m *pageMap) assembleResources(s string, p *pageState, parentBucket *pagesM
 	var err error
 
 	m.resources.WalkPrefix(s, func(s string, v any) bool {
-		panic("assemble resources not ready yet")
+		n := v.(*contentNode)
+		meta := n.fi.Meta()
+		classifier := meta.Classifier
+		var r resource.Resource
+		switch classifier {
+		case files.ContentClassContent:
+			var rp *pageState
+			rp, err = m.newPageFromContentNode(n, parentBucket, p)
+			if err != nil {
+				return true
+			}
+			rp.m.resourcePath = filepath.ToSlash(strings.TrimPrefix(rp.File().Path(), p.File().Dir()))
+			r = rp
+
+		case files.ContentClassFile:
+			r, err = m.newResource(n.fi, p)
+			if err != nil {
+				return true
+			}
+		default:
+			panic(fmt.Sprintf("invalid classifier: %q", classifier))
+		}
+
+		p.resources = append(p.resources, r)
+		return false
 	})
 
 	return err
 }
+
+func (m *pageMap) newResource(fim hugofs.FileMetaInfo, owner *pageState) (resource.Resource, error) {
+	if owner == nil {
+		panic("owner is nil")
+	}
+	// TODO(bep) consolidate with multihost logic + clean up
+	outputFormats := owner.m.outputFormats()
+	seen := make(map[string]bool)
+	var targetBasePaths []string
+	// Make sure bundled resources are published to all of the output formats'
+	// sub paths.
+	for _, f := range outputFormats {
+		p := f.Path
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		targetBasePaths = append(targetBasePaths, p)
+
+	}
+
+	meta := fim.Meta()
+	r := func() (hugio.ReadSeekCloser, error) {
+		return meta.Open()
+	}
+
+	target := strings.TrimPrefix(meta.Path, owner.File().Dir())
+
+	// Bundled resources are published next to their owner the first time
+	// their Permalink or RelPermalink is used.
+	return owner.s.ResourceSpec.New(
+		resources.ResourceSourceDescriptor{
+			TargetPaths:        owner.getTargetPaths,
+			OpenReadSeekCloser: r,
+			FileInfo:           fim,
+			RelTargetFilename:  target,
+			TargetBasePaths:    targetBasePaths,
+			LazyPublish:        true,
+		})
+}
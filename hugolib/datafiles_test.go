@@ -0,0 +1,136 @@
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestDataFiles(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- data/authors.toml --
+[bep]
+name = "Bjørn Erik"
+-- data/authors/bep.toml --
+name = "Overridden"
+twitter = "@bepsays"
+-- data/prices.csv --
+apple,1.20
+pear,0.80
+-- data/feed.xml --
+<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Feed</title><item><title>One</title></item><item><title>Two</title></item></channel></rss>
+-- data/version.xml --
+<version>1.2</version>
+-- data/colors.json --
+{ "primary": "red" }
+-- data/sizes.yaml --
+small: 10
+-- data/weights.yml --
+heavy: 100
+-- data/nested/conf.toml --
+[a]
+x = 1
+-- layouts/index.html --
+AUTH {{ .Site.Data.authors.bep.name }}|{{ .Site.Data.authors.bep.twitter }}
+{{ range .Site.Data.prices }}ROW {{ index . 0 }}={{ index . 1 }}|{{ end }}
+RSS {{ .Site.Data.feed.channel.title }}|{{ range .Site.Data.feed.channel.item }}{{ .title }},{{ end }}|v={{ index .Site.Data.feed "-version" }}
+VERSION {{ .Site.Data.version.version }}
+JSON {{ .Site.Data.colors.primary }}
+YAML {{ .Site.Data.sizes.small }}|{{ .Site.Data.weights.heavy }}
+NESTED {{ .Site.Data.nested.conf.a.x }}
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files}).Build()
+
+	b.AssertFileContent("index.html", `
+AUTH Overridden|@bepsays
+ROW apple=1.20|ROW pear=0.80|
+RSS Feed|One,Two,|v=2.0
+VERSION 1.2
+JSON red
+YAML 10|100
+NESTED 1
+`)
+	// The sub folder wins.
+	b.AssertLogContains("Data for key 'bep' in path 'authors.toml' is overridden by higher precedence data already in the data tree")
+}
+
+func TestDataFilesCSVConfig(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[data.csv]
+delimiter = ";"
+comment = "#"
+-- data/prices.csv --
+# item;price
+apple;1.20
+pear;0.80
+-- layouts/index.html --
+{{ range .Site.Data.prices }}ROW {{ len . }}:{{ index . 0 }}={{ index . 1 }}|{{ end }}
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files}).Build()
+
+	b.AssertFileContent("index.html", "ROW 2:apple=1.20|ROW 2:pear=0.80|", "! item")
+}
+
+func TestDataFilesCSVConfigInvalid(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[data.csv]
+delimiter = ";;"
+-- data/prices.csv --
+apple;1.20
+-- layouts/index.html --
+{{ .Site.Data.prices }}
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files})
+	if err := b.BuildE(); err == nil {
+		t.Fatal("expected error")
+	}
+	b.AssertLogContains(`failed to decode data.csv config: invalid character: ";;"`)
+}
+
+func TestDataFilesMultilingual(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "en"
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+dataDir = "data_fr"
+-- data/site.toml --
+greeting = "Hello"
+footer = "Shared"
+[nav]
+home = "Home"
+about = "About"
+-- data_fr/site.toml --
+greeting = "Bonjour"
+[nav]
+home = "Accueil"
+-- data_fr/only.toml --
+v = "fr only"
+-- layouts/index.html --
+{{ $d := .Site.Data.site }}{{ .Lang }}|{{ $d.greeting }}|{{ $d.footer }}|{{ $d.nav.home }}|{{ $d.nav.about }}|{{ with .Site.Data.only }}{{ .v }}{{ else }}none{{ end }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("index.html", "en|Hello|Shared|Home|About|none")
+	b.AssertFileContent("fr/index.html", "fr|Bonjour|Shared|Accueil|About|fr only")
+
+	if got := b.H.Data("en")["site"].(map[string]any)["greeting"]; got != "Hello" {
+		t.Errorf("got en greeting %v", got)
+	}
+	if got := b.H.Data("fr")["only"].(map[string]any)["v"]; got != "fr only" {
+		t.Errorf("got fr only %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package hugolib
+
+import (
+	jww "github.com/spf13/jwalterweatherman"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fileCacheTestFiles = `
+-- config.toml --
+baseURL = "https://example.org/"
+CONFIG
+-- assets/css/a.css --
+body {   color: red;   }
+-- assets/css/b.css --
+p {   color: blue;   }
+-- layouts/index.html --
+{{ (resources.Get "css/a.css" | minify).Content }}|{{ (resources.Get "css/b.css" | minify).Content }}
+`
+
+func assertCachedFiles(t *testing.T, pattern string, expect int) {
+	t.Helper()
+	m, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != expect {
+		t.Fatalf("got %d files matching %s, want %d: %v", len(m), pattern, expect, m)
+	}
+}
+
+func TestFileCachesPrune(t *testing.T) {
+	files := strings.Replace(fileCacheTestFiles, "CONFIG", "", 1)
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files, LogLevel: jww.LevelInfo}).Build()
+	b.AssertFileContent("index.html", "body{color:red}|p{color:blue}")
+
+	gen := filepath.Join(b.Cfg.WorkingDir, "resources", "_gen", "assets", "css", "css")
+	assertCachedFiles(t, filepath.Join(gen, "a.css_*"), 2)
+	assertCachedFiles(t, filepath.Join(gen, "b.css_*"), 2)
+
+	// Stop using b.css; its cache entries are removed after the next build.
+	b.Cfg.TxtarString = strings.Replace(files, `|{{ (resources.Get "css/b.css" | minify).Content }}`, "", 1)
+	b.Build()
+	b.AssertFileContent("index.html", "body{color:red}", "! p{color:blue}")
+	b.AssertLogContains("Removed 2 unused file(s) from the file caches")
+
+	assertCachedFiles(t, filepath.Join(gen, "a.css_*"), 2)
+	assertCachedFiles(t, filepath.Join(gen, "b.css_*"), 0)
+}
+
+func TestFileCachesConfig(t *testing.T) {
+	files := strings.Replace(fileCacheTestFiles, "CONFIG", `
+[caches.assets]
+dir = ":cacheDir/myassets"
+maxAge = "1h"
+`, 1)
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files}).Build()
+	b.AssertFileContent("index.html", "body{color:red}|p{color:blue}")
+
+	wd := b.Cfg.WorkingDir
+	assertCachedFiles(t, filepath.Join(wd, "_cache", "myassets", "filecache", "assets", "css", "css", "*.css_*"), 4)
+	assertCachedFiles(t, filepath.Join(wd, "resources", "_gen", "assets", "*"), 0)
+}
+
+func TestFileCachesIgnoreCache(t *testing.T) {
+	files := strings.Replace(fileCacheTestFiles, "CONFIG", "ignoreCache = true", 1)
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files}).Build()
+	b.AssertFileContent("index.html", "body{color:red}|p{color:blue}")
+
+	assertCachedFiles(t, filepath.Join(b.Cfg.WorkingDir, "resources", "_gen", "assets", "css", "css", "*"), 0)
+}
@@ -495,7 +495,7 @@ func (b *sourceFilesystemsBuilder) Build() (*SourceFilesystems, error) {
 	contentDirs := b.theBigFs.overlayDirs[files.ComponentFolderContent]
 	contentBfs := afero.NewBasePathFs(b.theBigFs.overlayMountsContent, files.ComponentFolderContent)
 
-	contentFs, err := hugofs.NewLanguageFs(b.p.LanguagesDefaultFirst.AsOrdinalSet(), contentBfs)
+	contentFs, err := hugofs.NewLanguageFs(b.p.LanguagesDefaultFirst.AsOrdinalSet(), b.p.ContentFileExtensions, contentBfs)
 	if err != nil {
 		return nil, fmt.Errorf("create content filesystem: %w", err)
 	}
@@ -611,7 +611,7 @@ func (b *sourceFilesystemsBuilder) createOverlayFs(
 
 			lang := mount.Lang
 			if lang == "" && isContentMount {
-				lang = "en"
+				lang = b.p.DefaultContentLanguage
 			}
 			rm.Meta.Lang = lang
 
@@ -35,6 +35,9 @@ type hugoSitesInit struct {
 
 	// Performs late initialization (before render) of the templates.
 	layouts *lazy.Init
+
+	// Maps page translations.
+	translations *lazy.Init
 }
 
 // HugoSites represents the sites to build. Each site represents a language.
@@ -56,8 +59,8 @@ type HugoSites struct {
 	workers    *para.Workers
 	numWorkers int
 
-	// As loaded from the /data dirs
-	data map[string]any
+	// As loaded from the /data dirs, per language.
+	data map[string]map[string]any
 }
 
 // NewHugoSites creates HugoSites from the given config.
@@ -115,8 +118,9 @@ func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
 		workers:    workers,    // nil
 		numWorkers: numWorkers, // 1
 		init: &hugoSitesInit{
-			data:    lazy.New(),
-			layouts: lazy.New(),
+			data:         lazy.New(),
+			layouts:      lazy.New(),
+			translations: lazy.New(),
 		},
 	}
 
@@ -141,6 +145,16 @@ func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
 		return nil, nil
 	})
 
+	log.Process("newHugoSites", "add translations to h.init")
+	h.init.translations.Add(func() (any, error) {
+		if len(h.Sites) > 1 {
+			allTranslations := pagesToTranslationsMap(h.Sites)
+			assignTranslationsToPages(allTranslations, h.Sites)
+		}
+
+		return nil, nil
+	})
+
 	for _, s := range sites {
 		s.h = h
 	}
@@ -162,24 +176,81 @@ func newHugoSites(cfg deps.DepsCfg, sites ...*Site) (*HugoSites, error) {
 func (h *HugoSites) loadData(fis []hugofs.FileMetaInfo) (err error) {
 	spec := source.NewSourceSpec(h.PathSpec, nil, nil)
 
-	h.data = make(map[string]any)
-	for _, fi := range fis {
-		fileSystem := spec.NewFilesystemFromFileMetaInfo(fi)
-		files, err := fileSystem.Files()
+	// CSV files use the delimiter and comment character set in [data.csv], e.g.
+	// delimiter = ";" and comment = "#".
+	decoder, err := metadecoders.DecodeDecoder(h.Cfg.GetStringMap("data.csv"))
+	if err != nil {
+		return fmt.Errorf("failed to decode data.csv config: %w", err)
+	}
+
+	// Data mounted for a given language is kept apart from the data shared
+	// by all languages.
+	load := func(lang string) (map[string]any, error) {
+		data := make(map[string]any)
+		for _, fi := range fis {
+			if fi.Meta().Lang != lang {
+				continue
+			}
+			fileSystem := spec.NewFilesystemFromFileMetaInfo(fi)
+			files, err := fileSystem.Files()
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range files {
+				if err := h.handleDataFile(data, decoder, r); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return data, nil
+	}
+
+	shared, err := load("")
+	if err != nil {
+		return err
+	}
+
+	h.data = make(map[string]map[string]any)
+	for _, s := range h.Sites {
+		lang := s.Lang()
+		if _, found := h.data[lang]; found {
+			continue
+		}
+		translated, err := load(lang)
 		if err != nil {
 			return err
 		}
-		for _, r := range files {
-			if err := h.handleDataFile(r); err != nil {
-				return err
-			}
-		}
+		h.data[lang] = overlayData(shared, translated)
 	}
 
 	return
 }
 
-func (h *HugoSites) handleDataFile(r source.File) error {
+// overlayData returns base with the entries in overlay added. Entries in
+// overlay win, nested maps are merged.
+func overlayData(base, overlay map[string]any) map[string]any {
+	if len(overlay) == 0 {
+		return base
+	}
+
+	m := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		m[k] = v
+	}
+	for k, v := range overlay {
+		bm, ok1 := m[k].(map[string]any)
+		om, ok2 := v.(map[string]any)
+		if ok1 && ok2 {
+			m[k] = overlayData(bm, om)
+		} else {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+func (h *HugoSites) handleDataFile(data map[string]any, decoder metadecoders.Decoder, r source.File) error {
 	var current map[string]any
 
 	f, err := r.FileInfo().Meta().Open()
@@ -189,7 +260,7 @@ func (h *HugoSites) handleDataFile(r source.File) error {
 	defer f.Close()
 
 	// Crawl in data tree to insert data
-	current = h.data
+	current = data
 	keyParts := strings.Split(r.Dir(), helpers.FilePathSeparator)
 
 	for _, key := range keyParts {
@@ -201,57 +272,56 @@ func (h *HugoSites) handleDataFile(r source.File) error {
 		}
 	}
 
-	data, err := h.readData(r)
+	value, err := h.readData(decoder, r)
 	if err != nil {
 		return err
 	}
 
-	if data == nil {
+	if value == nil {
 		return nil
 	}
 
 	// filepath.Walk walks the files in lexical order, '/' comes before '.'
 	higherPrecedentData := current[r.BaseFileName()]
 
-	switch data.(type) {
+	switch value.(type) {
 	case nil:
 	case map[string]any:
 
 		switch higherPrecedentData.(type) {
 		case nil:
-			current[r.BaseFileName()] = data
+			current[r.BaseFileName()] = value
 		case map[string]any:
 			// merge maps: insert entries from data for keys that
 			// don't already exist in higherPrecedentData
 			higherPrecedentMap := higherPrecedentData.(map[string]any)
-			for key, value := range data.(map[string]any) {
+			for key, v := range value.(map[string]any) {
 				if _, exists := higherPrecedentMap[key]; exists {
 					// this warning could happen if
 					// 1. A theme uses the same key; the main data folder wins
 					// 2. A sub folder uses the same key: the sub folder wins
-					// TODO(bep) figure out a way to detect 2) above and make that a WARN
-					fmt.Printf("Data for key '%s' in path '%s' is overridden by higher precedence data already in the data tree", key, r.Path())
+					h.Log.Warnf("Data for key '%s' in path '%s' is overridden by higher precedence data already in the data tree", key, r.Path())
 				} else {
-					higherPrecedentMap[key] = value
+					higherPrecedentMap[key] = v
 				}
 			}
 		default:
 			// can't merge: higherPrecedentData is not a map
-			fmt.Printf("The %T data from '%s' overridden by "+
-				"higher precedence %T data already in the data tree", data, r.Path(), higherPrecedentData)
+			h.Log.Warnf("The %T data from '%s' overridden by "+
+				"higher precedence %T data already in the data tree", value, r.Path(), higherPrecedentData)
 		}
 
-	case []any:
+	case []any, [][]string:
 		if higherPrecedentData == nil {
-			current[r.BaseFileName()] = data
+			current[r.BaseFileName()] = value
 		} else {
 			// we don't merge array data
-			fmt.Printf("The %T data from '%s' overridden by "+
-				"higher precedence %T data already in the data tree", data, r.Path(), higherPrecedentData)
+			h.Log.Warnf("The %T data from '%s' overridden by "+
+				"higher precedence %T data already in the data tree", value, r.Path(), higherPrecedentData)
 		}
 
 	default:
-		fmt.Printf("unexpected data type %T in file %s", data, r.LogicalName())
+		h.Log.Errorf("unexpected data type %T in file %s", value, r.LogicalName())
 	}
 
 	return nil
@@ -275,6 +345,10 @@ func (l configLoader) applyDeps(cfg deps.DepsCfg, sites ...*Site) error {
 		onCreated := func(d *deps.Deps) error {
 			s.Deps = d
 
+			// The page sort cache is shared by all sites in the process,
+			// so start every build with an empty one.
+			d.BuildStartListeners.Add(page.ResetCaches)
+
 			log.Process("applyDeps-onCreate", "set site publisher as DestinationPublisher")
 			// Set up the main publishing chain.
 			pub, err := publisher.NewDestinationPublisher(
@@ -312,12 +386,22 @@ func (l configLoader) applyDeps(cfg deps.DepsCfg, sites ...*Site) error {
 		cfg.OutputFormats = s.outputFormatsConfig
 
 		var err error
+		prev := d
 		log.Process("applyDeps", "new deps")
 		d, err = deps.New(cfg)
 		if err != nil {
 			return fmt.Errorf("create deps: %w", err)
 		}
 
+		if prev != nil {
+			// Share one metrics store so the report covers all sites.
+			d.Metrics = prev.Metrics
+		}
+
+		// The file caches are pruned after the build based on what all
+		// the sites used, so they must be shared.
+		cfg.FileCaches = d.FileCaches
+
 		d.OutputFormatsConfig = s.outputFormatsConfig
 
 		if err := onCreated(d); err != nil {
@@ -333,7 +417,7 @@ func (l configLoader) applyDeps(cfg deps.DepsCfg, sites ...*Site) error {
 	return nil
 }
 
-func (h *HugoSites) readData(f source.File) (any, error) {
+func (h *HugoSites) readData(decoder metadecoders.Decoder, f source.File) (any, error) {
 	file, err := f.FileInfo().Meta().Open()
 	if err != nil {
 		return nil, fmt.Errorf("readData: failed to open data file: %w", err)
@@ -342,15 +426,16 @@ func (h *HugoSites) readData(f source.File) (any, error) {
 	content := helpers.ReaderToBytes(file)
 
 	format := metadecoders.FormatFromString(f.Ext())
-	return metadecoders.Default.Unmarshal(content, format)
+	return decoder.Unmarshal(content, format)
 }
 
-func (h *HugoSites) Data() map[string]any {
+// Data returns the data tree for the given language.
+func (h *HugoSites) Data(lang string) map[string]any {
 	if _, err := h.init.data.Do(); err != nil {
-		fmt.Errorf("failed to load data: %w", err)
+		h.Log.Errorf("failed to load data: %s", err)
 		return nil
 	}
-	return h.data
+	return h.data[lang]
 }
 
 func (s *Site) withSiteTemplates(withTemplates ...func(templ tpl.TemplateManager) error) func(templ tpl.TemplateManager) error {
@@ -407,6 +492,26 @@ func (h *HugoSites) siteInfos() page.Sites {
 }
 
 func (h *HugoSites) createPageCollections() error {
+	allPages := newLazyPagesFactory(func() page.Pages {
+		var pages page.Pages
+		for _, s := range h.Sites {
+			pages = append(pages, s.Pages()...)
+		}
+
+		page.SortByDefault(pages)
+
+		return pages
+	})
+
+	allRegularPages := newLazyPagesFactory(func() page.Pages {
+		return h.findPagesByKindIn(page.KindPage, allPages.get())
+	})
+
+	for _, s := range h.Sites {
+		s.PageCollections.allPages = allPages
+		s.PageCollections.allRegularPages = allRegularPages
+	}
+
 	return nil
 }
 
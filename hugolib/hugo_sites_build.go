@@ -1,6 +1,8 @@
 package hugolib
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/sunwei/hugo-playground/log"
 	"github.com/sunwei/hugo-playground/output"
 	"github.com/sunwei/hugo-playground/resources/page/pagemeta"
@@ -14,6 +16,14 @@ func (h *HugoSites) Build(config BuildCfg) error {
 	log.Process("HugoSites Build", "start")
 	conf := &config
 
+	for _, s := range h.Sites {
+		s.Deps.BuildStartListeners.Notify()
+	}
+
+	if h.Metrics != nil {
+		h.Metrics.Reset()
+	}
+
 	// process file system to create content map
 	err := h.process(conf)
 	if err != nil {
@@ -31,6 +41,27 @@ func (h *HugoSites) Build(config BuildCfg) error {
 		return err
 	}
 
+	if h.Metrics != nil {
+		var b bytes.Buffer
+		h.Metrics.WriteMetrics(&b)
+
+		h.Log.Printf("\nTemplate Metrics:\n\n")
+		h.Log.Println(b.String())
+	}
+
+	if errorCount := h.Log.LogCounters().ErrorCounter.Count(); errorCount > 0 {
+		return fmt.Errorf("logged %d error(s)", errorCount)
+	}
+
+	// Remove the file cache entries not used in this build.
+	count, err := h.Deps.FileCaches.Prune()
+	if err != nil {
+		return fmt.Errorf("failed to prune file caches: %w", err)
+	}
+	if count > 0 {
+		h.Log.Infof("Removed %d unused file(s) from the file caches", count)
+	}
+
 	log.Process("HugoSites Build", "done")
 	return nil
 }
@@ -48,6 +79,10 @@ func (h *HugoSites) assemble(bcfg *BuildCfg) error {
 		return err
 	}
 
+	if err := h.createPageCollections(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -102,6 +137,11 @@ func (h *HugoSites) render(config *BuildCfg) error {
 		return err
 	}
 
+	log.Process("hugoSite render", "main language redirect")
+	if err := h.Sites[0].renderMainLanguageRedirect(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
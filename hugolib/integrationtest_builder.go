@@ -0,0 +1,173 @@
+package hugolib
+
+import (
+	"bytes"
+	"github.com/spf13/afero"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/sunwei/hugo-playground/common/loggers"
+	"github.com/sunwei/hugo-playground/deps"
+	"github.com/sunwei/hugo-playground/hugofs"
+	"golang.org/x/tools/txtar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test is a convenience method to create a new IntegrationTestBuilder from some files and run a build.
+func Test(t testing.TB, files string) *IntegrationTestBuilder {
+	return NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files}).Build()
+}
+
+// IntegrationTestConfig configures an IntegrationTestBuilder.
+type IntegrationTestConfig struct {
+	T testing.TB
+
+	// The files to write to the working dir, in txtar format.
+	TxtarString string
+
+	// The working dir to build in. A temporary dir is used if not set.
+	// Files already in the dir are kept, which allows the same project to
+	// be built more than once.
+	WorkingDir string
+
+	// The log threshold, defaults to WARN.
+	LogLevel jww.Threshold
+}
+
+// IntegrationTestBuilder builds a site from files on disk and asserts on the
+// published output and the log.
+type IntegrationTestBuilder struct {
+	Cfg IntegrationTestConfig
+
+	// The sites from the last build.
+	H *HugoSites
+
+	logBuff bytes.Buffer
+}
+
+// NewIntegrationTestBuilder creates a new IntegrationTestBuilder.
+func NewIntegrationTestBuilder(conf IntegrationTestConfig) *IntegrationTestBuilder {
+	if conf.WorkingDir == "" {
+		conf.WorkingDir = conf.T.TempDir()
+	}
+	if conf.LogLevel == 0 {
+		conf.LogLevel = jww.LevelWarn
+	}
+	return &IntegrationTestBuilder{Cfg: conf}
+}
+
+// Build builds the site and fails the test on any error.
+func (b *IntegrationTestBuilder) Build() *IntegrationTestBuilder {
+	b.Cfg.T.Helper()
+	if err := b.BuildE(); err != nil {
+		b.Cfg.T.Fatalf("build failed: %s\nlog:\n%s", err, b.logBuff.String())
+	}
+	return b
+}
+
+// BuildE builds the site and returns any error.
+func (b *IntegrationTestBuilder) BuildE() error {
+	b.logBuff.Reset()
+
+	afs := afero.NewOsFs()
+	wd := b.Cfg.WorkingDir
+
+	for _, f := range txtar.Parse([]byte(b.Cfg.TxtarString)).Files {
+		filename := filepath.Join(wd, filepath.FromSlash(f.Name))
+		if err := afs.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			return err
+		}
+		content := bytes.TrimSuffix(f.Data, []byte("\n"))
+		if err := afero.WriteFile(afs, filename, content, 0666); err != nil {
+			return err
+		}
+	}
+
+	if err := afs.RemoveAll(filepath.Join(wd, "public")); err != nil {
+		return err
+	}
+
+	cfg, _, err := LoadConfig(ConfigSourceDescriptor{WorkingDir: wd, Fs: afs, Filename: "config.toml"})
+	if err != nil {
+		return err
+	}
+	if !cfg.IsSet("cacheDir") {
+		// Keep the file caches with the project, not in the shared temp dir.
+		cfg.Set("cacheDir", filepath.Join(wd, "_cache"))
+	}
+
+	logger := loggers.NewBasicLoggerForWriter(b.Cfg.LogLevel, &b.logBuff)
+	h, err := NewHugoSites(deps.DepsCfg{Cfg: cfg, Fs: hugofs.NewFrom(afs, cfg, wd), Logger: logger})
+	if err != nil {
+		return err
+	}
+	b.H = h
+
+	return h.Build(BuildCfg{})
+}
+
+// FileContent returns the content of the given file in /public.
+func (b *IntegrationTestBuilder) FileContent(filename string) string {
+	b.Cfg.T.Helper()
+	content, err := os.ReadFile(b.publicFilename(filename))
+	if err != nil {
+		b.Cfg.T.Fatal(err)
+	}
+	return string(content)
+}
+
+// AssertFileContent asserts that the given file in /public contains all of
+// the given matches. Each line in a match is checked on its own, with leading
+// and trailing whitespace ignored. Lines starting with "! " must not be in
+// the file.
+func (b *IntegrationTestBuilder) AssertFileContent(filename string, matches ...string) {
+	b.Cfg.T.Helper()
+	content := b.FileContent(filename)
+	for _, m := range matches {
+		for _, line := range strings.Split(m, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if negate := strings.TrimPrefix(line, "! "); negate != line {
+				if strings.Contains(content, negate) {
+					b.Cfg.T.Errorf("%s: unexpected %q in:\n%s", filename, negate, content)
+				}
+				continue
+			}
+			if !strings.Contains(content, line) {
+				b.Cfg.T.Errorf("%s: expected %q in:\n%s", filename, line, content)
+			}
+		}
+	}
+}
+
+// AssertFileExists asserts whether the given file in /public exists.
+func (b *IntegrationTestBuilder) AssertFileExists(filename string, exists bool) {
+	b.Cfg.T.Helper()
+	_, err := os.Stat(b.publicFilename(filename))
+	if found := err == nil; found != exists {
+		b.Cfg.T.Errorf("%s: expected exists=%t", filename, exists)
+	}
+}
+
+// AssertLogContains asserts that the log of the last build contains s.
+func (b *IntegrationTestBuilder) AssertLogContains(s string) {
+	b.Cfg.T.Helper()
+	if !strings.Contains(b.logBuff.String(), s) {
+		b.Cfg.T.Errorf("expected %q in log:\n%s", s, b.logBuff.String())
+	}
+}
+
+// AssertLogNotContains asserts that the log of the last build does not contain s.
+func (b *IntegrationTestBuilder) AssertLogNotContains(s string) {
+	b.Cfg.T.Helper()
+	if strings.Contains(b.logBuff.String(), s) {
+		b.Cfg.T.Errorf("unexpected %q in log:\n%s", s, b.logBuff.String())
+	}
+}
+
+func (b *IntegrationTestBuilder) publicFilename(filename string) string {
+	return filepath.Join(b.Cfg.WorkingDir, "public", filepath.FromSlash(filename))
+}
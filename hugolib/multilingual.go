@@ -7,6 +7,10 @@ import (
 )
 
 func getLanguages(cfg config.Provider) langs.Languages {
-	log.Process("NewLanguages", "create multiple languages, only 'en' in our case")
+	log.Process("NewLanguages", "create one language per [languages] entry, or the default language")
+	if cfg.IsSet("languagesSorted") {
+		return cfg.Get("languagesSorted").(langs.Languages)
+	}
+
 	return langs.Languages{langs.NewDefaultLanguage(cfg)}
 }
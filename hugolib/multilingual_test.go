@@ -0,0 +1,113 @@
+package hugolib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultilingual(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "en"
+[params]
+greeting = "Hello"
+[languages]
+[languages.en]
+weight = 1
+title = "English Site"
+[languages.fr]
+weight = 2
+title = "Site Français"
+[languages.fr.params]
+greeting = "Bonjour"
+-- content/blog/post.md --
+---
+title: "Post EN"
+---
+-- content/blog/post.fr.md --
+---
+title: "Post FR"
+---
+-- content/about.md --
+---
+title: "About"
+---
+-- layouts/index.html --
+HOME {{ .Lang }}|{{ .Site.Title }}|{{ .Site.Params.greeting }}|{{ .RelPermalink }}|P:{{ range .Site.RegularPages }}{{ .Title }}:{{ .RelPermalink }},{{ end }}|T:{{ range .Translations }}{{ .Lang }}:{{ .RelPermalink }},{{ end }}
+-- layouts/_default/single.html --
+SINGLE {{ .Lang }}|{{ .Title }}|{{ .Permalink }}|{{ .IsTranslated }}|T:{{ range .Translations }}{{ .Lang }}:{{ .RelPermalink }},{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Lang }}|{{ .Title }}|{{ .RelPermalink }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("index.html", "HOME en|English Site|Hello|/|P:About:/about/,Post EN:/blog/post/,|T:fr:/fr/,")
+	b.AssertFileContent("fr/index.html", "HOME fr|Site Français|Bonjour|/fr/|P:Post FR:/fr/blog/post/,|T:en:/,")
+	b.AssertFileContent("blog/post/index.html", "SINGLE en|Post EN|https://example.org/blog/post/|true|T:fr:/fr/blog/post/,")
+	b.AssertFileContent("fr/blog/post/index.html", "SINGLE fr|Post FR|https://example.org/fr/blog/post/|true|T:en:/blog/post/,")
+	b.AssertFileContent("about/index.html", "SINGLE en|About|https://example.org/about/|false|T:")
+	b.AssertFileContent("fr/blog/index.html", "LIST fr|Blog|/fr/blog/")
+	b.AssertFileExists("fr/about/index.html", false)
+	b.AssertFileContent("en/index.html", `<meta http-equiv="refresh" content="0; url=https://example.org/">`)
+}
+
+func TestMultilingualContentDirInSubdir(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "fr"
+defaultContentLanguageInSubdir = true
+[languages]
+[languages.en]
+weight = 2
+contentDir = "content/en"
+[languages.fr]
+weight = 1
+contentDir = "content/fr"
+-- content/en/blog/post.md --
+---
+title: "Post EN"
+translationKey: "p1"
+---
+-- content/fr/blog/article.md --
+---
+title: "Post FR"
+translationKey: "p1"
+---
+-- layouts/index.html --
+HOME {{ .Lang }}|{{ .RelPermalink }}
+-- layouts/_default/single.html --
+SINGLE {{ .Lang }}|{{ .Title }}|{{ .RelPermalink }}|A:{{ range .AllTranslations }}{{ .Lang }}:{{ .Title }},{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Lang }}|{{ .Title }}|{{ .RelPermalink }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("fr/index.html", "HOME fr|/fr/")
+	b.AssertFileContent("en/index.html", "HOME en|/en/")
+	b.AssertFileContent("fr/blog/article/index.html", "SINGLE fr|Post FR|/fr/blog/article/|A:fr:Post FR,en:Post EN,")
+	b.AssertFileContent("en/blog/post/index.html", "SINGLE en|Post EN|/en/blog/post/|A:fr:Post FR,en:Post EN,")
+	b.AssertFileContent("index.html", `<meta http-equiv="refresh" content="0; url=https://example.org/fr/">`)
+}
+
+func TestMultilingualUndefinedDefaultLanguage(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "de"
+[languages]
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil || !strings.Contains(err.Error(), `defaultContentLanguage does not match any language definition`) {
+		t.Fatalf("expected undefined default language error, got %v", err)
+	}
+}
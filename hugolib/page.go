@@ -13,11 +13,13 @@ import (
 	"github.com/sunwei/hugo-playground/output"
 	"github.com/sunwei/hugo-playground/parser/metadecoders"
 	"github.com/sunwei/hugo-playground/parser/pageparser"
+	"github.com/sunwei/hugo-playground/resources"
 	"github.com/sunwei/hugo-playground/resources/page"
 	"github.com/sunwei/hugo-playground/resources/resource"
 	"github.com/sunwei/hugo-playground/source"
 	"github.com/sunwei/hugo-playground/tpl"
 	"go.uber.org/atomic"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -51,6 +53,34 @@ type pageState struct {
 	*pageCommon
 }
 
+type pageSiteAdapter struct {
+	p page.Page
+	s *Site
+}
+
+func (pa pageSiteAdapter) GetPageWithTemplateInfo(info tpl.Info, ref string) (page.Page, error) {
+	p, err := pa.GetPage(ref)
+	if p != nil {
+		// Track pages referenced by templates/shortcodes
+		// when in server mode.
+		if im, ok := info.(identity.Manager); ok {
+			im.Add(p)
+		}
+	}
+	return p, err
+}
+
+func (pa pageSiteAdapter) GetPage(ref string) (page.Page, error) {
+	p, err := pa.s.getPageNew(pa.p, ref)
+	if p == nil {
+		// The nil struct has meaning in some situations, mostly to avoid breaking
+		// existing sites doing $nilpage.IsDescendant($p), which will always return
+		// false.
+		p = page.NilPage
+	}
+	return p, err
+}
+
 func (p *pageState) Err() resource.ResourceError {
 	return nil
 }
@@ -74,6 +104,14 @@ func (s *Site) sectionsFromFile(fi source.File) []string {
 	return parts
 }
 
+func (p *pageState) HasShortcode(name string) bool {
+	if p.shortcodeState == nil {
+		return false
+	}
+
+	return p.shortcodeState.hasName(name)
+}
+
 func (p *pageState) mapContent(bucket *pagesMapBucket, meta *pageMeta) error {
 	p.cmap = &pageContentMap{
 		items: make([]any, 0, 20),
@@ -81,6 +119,7 @@ func (p *pageState) mapContent(bucket *pagesMapBucket, meta *pageMeta) error {
 
 	return p.mapContentForResult(
 		p.source.parsed,
+		p.shortcodeState,
 		p.cmap,
 		meta.markup,
 		func(m map[string]interface{}) error {
@@ -91,6 +130,7 @@ func (p *pageState) mapContent(bucket *pagesMapBucket, meta *pageMeta) error {
 
 func (p *pageState) mapContentForResult(
 	result pageparser.Result,
+	s *shortcodeHandler,
 	rn *pageContentMap,
 	markup string,
 	withFrontMatter func(map[string]any) error,
@@ -99,11 +139,13 @@ func (p *pageState) mapContentForResult(
 	iter := result.Iterator()
 
 	fail := func(err error, i pageparser.Item) error {
-		return errors.New("fail fail fail")
+		return p.parseError(err, result.Input(), i.Pos())
 	}
 
 	// the parser is guaranteed to return items in proper order or fail, so …
 	// … it's safe to keep some "global" state
+	var currShortcode shortcode
+	var ordinal int
 	var frontMatterSet bool
 
 Loop:
@@ -163,12 +205,42 @@ Loop:
 				// and we need to track the summary.
 				rn.AddReplacement(internalSummaryDividerPre, it)
 			}
+
+		// Handle shortcode
+		case it.IsLeftShortcodeDelim():
+			// let extractShortcode handle left delim (will do so recursively)
+			iter.Backup()
+
+			currShortcode, err := s.extractShortcode(ordinal, 0, result.Input(), iter)
+			if err != nil {
+				return fail(err, it)
+			}
+
+			currShortcode.pos = it.Pos()
+			currShortcode.length = iter.Current().Pos() - it.Pos()
+
+			if currShortcode.name != "" {
+				s.addName(currShortcode.name)
+			}
+
+			if currShortcode.params == nil {
+				var s []string
+				currShortcode.params = s
+			}
+
+			currShortcode.placeholder = createShortcodePlaceholder("s", ordinal)
+			ordinal++
+			s.shortcodes = append(s.shortcodes, currShortcode)
+
+			rn.AddShortcode(currShortcode)
+
 		case it.Type == pageparser.TypeEmoji:
 			rn.AddBytes(it)
 		case it.IsEOF():
 			break Loop
 		case it.IsError():
 			err := fail(errors.New(it.ValStr(result.Input())), it)
+			currShortcode.err = err
 			return err
 
 		default:
@@ -188,7 +260,7 @@ Loop:
 }
 
 func (p *pageState) parseError(err error, input []byte, offset int) error {
-	return errors.New("pos")
+	return fmt.Errorf("%s: %w", p.posFromInput(input, offset), err)
 }
 
 func (p *pageState) posFromInput(input []byte, offset int) text.Position {
@@ -222,6 +294,66 @@ func (p *pageState) pathOrTitle() string {
 	return p.Title()
 }
 
+// Eq returns whether the current page equals the given page.
+// This is what's invoked when doing `{{ if eq $page $otherPage }}`
+func (p *pageState) Eq(other any) bool {
+	pp, err := unwrapPage(other)
+	if err != nil {
+		return false
+	}
+
+	return p == pp
+}
+
+// IsTranslated returns whether this content file is translated to
+// other language(s).
+func (p *pageState) IsTranslated() bool {
+	p.s.h.init.translations.Do()
+	return len(p.translations) > 0
+}
+
+// TranslationKey returns the key used to map language translations of this page.
+// It will use the translationKey set in front matter if set, or the content path and
+// filename (excluding any language code and extension), e.g. "about/index".
+// The Page Kind is always prepended.
+func (p *pageState) TranslationKey() string {
+	p.translationKeyInit.Do(func() {
+		if p.m.translationKey != "" {
+			p.translationKey = p.Kind() + "/" + p.m.translationKey
+		} else if p.IsPage() && !p.File().IsZero() {
+			p.translationKey = path.Join(p.Kind(), filepath.ToSlash(p.File().Dir()), p.File().TranslationBaseName())
+		} else if p.IsNode() {
+			p.translationKey = path.Join(p.Kind(), p.SectionsPath())
+		}
+	})
+
+	return p.translationKey
+}
+
+// AllTranslations returns all translations, including the current Page.
+func (p *pageState) AllTranslations() page.Pages {
+	p.s.h.init.translations.Do()
+	return p.allTranslations
+}
+
+// Translations returns the translations excluding the current Page.
+func (p *pageState) Translations() page.Pages {
+	p.s.h.init.translations.Do()
+	return p.translations
+}
+
+func (p *pageState) setTranslations(pages page.Pages) {
+	p.allTranslations = pages
+	page.SortByLanguage(p.allTranslations)
+	translations := make(page.Pages, 0)
+	for _, t := range p.allTranslations {
+		if !t.Eq(p) {
+			translations = append(translations, t)
+		}
+	}
+	p.translations = translations
+}
+
 func (p *pageState) GetIdentity() identity.Identity {
 	return identity.NewPathIdentity(files.ComponentFolderContent, filepath.FromSlash(p.Pathc()))
 }
@@ -247,8 +379,16 @@ func (p *pageState) AlternativeOutputFormats() page.OutputFormats {
 }
 
 func (ps *pageState) initCommonProviders(pp pagePaths) error {
+	if ps.IsPage() {
+		ps.posNextPrev = &nextPrev{init: ps.s.init.prevNext}
+		ps.posNextPrevSection = &nextPrev{init: ps.s.init.prevNextInSection}
+		ps.InSectionPositioner = newPagePositionInSection(ps.posNextPrevSection)
+		ps.Positioner = newPagePosition(ps.posNextPrev)
+	}
+
 	ps.OutputFormatsProvider = pp
 	ps.targetPathDescriptor = pp.targetPathDescriptor
+	ps.RefProvider = newPageRef(ps)
 	ps.SitesProvider = ps.s.Info
 
 	return nil
@@ -378,7 +518,26 @@ func (p *pageState) shiftToOutputFormat(isRenderingSite bool, idx int) error {
 		log.Process("pageState", "init contentProvider with page content output")
 		p.pageOutput.initContentProvider(cp)
 	} else {
-		panic("not ready for unrendering site yet")
+		// We attempt to assign pageContentOutputs while preparing each site
+		// for rendering and before rendering each site. This lets us share
+		// content between page outputs to conserve resources. But if a template
+		// unexpectedly calls a method of a ContentProvider that is not yet
+		// initialized, we assign a LazyContentProvider that performs the
+		// initialization just in time.
+		if lcp, ok := (p.pageOutput.ContentProvider.(*page.LazyContentProvider)); ok {
+			lcp.Reset()
+		} else {
+			lcp = page.NewLazyContentProvider(func() (page.OutputFormatContentProvider, error) {
+				cp, err := newPageContentOutput(p, p.pageOutput)
+				if err != nil {
+					return nil, err
+				}
+				return cp, nil
+			})
+			p.pageOutput.ContentProvider = lcp
+			p.pageOutput.TableOfContentsProvider = lcp
+			p.pageOutput.PageRenderProvider = lcp
+		}
 	}
 
 	return nil
@@ -396,7 +555,8 @@ func (p *pageState) Resources() resource.Resources {
 	p.resourcesInit.Do(func() {
 		p.sortResources()
 		if len(p.m.resourcesMetadata) > 0 {
-			panic("resources metadata not supported yet")
+			resources.AssignMetadata(p.m.resourcesMetadata, p.resources...)
+			p.sortResources()
 		}
 	})
 	return p.resources
@@ -3,12 +3,29 @@ package hugolib
 import (
 	"github.com/sunwei/hugo-playground/compare"
 	"github.com/sunwei/hugo-playground/lazy"
+	"github.com/sunwei/hugo-playground/navigation"
 	"github.com/sunwei/hugo-playground/output"
 	"github.com/sunwei/hugo-playground/resources/page"
 	"github.com/sunwei/hugo-playground/resources/resource"
 	"sync"
 )
 
+type nextPrevProvider interface {
+	getNextPrev() *nextPrev
+}
+
+func (p *pageCommon) getNextPrev() *nextPrev {
+	return p.posNextPrev
+}
+
+type nextPrevInSectionProvider interface {
+	getNextPrevInSection() *nextPrev
+}
+
+func (p *pageCommon) getNextPrevInSection() *nextPrev {
+	return p.posNextPrevSection
+}
+
 type pageCommon struct {
 	s *Site
 	m *pageMeta
@@ -22,10 +39,18 @@ type pageCommon struct {
 	// All of these represents the common parts of a page.Page
 	page.ChildCareProvider
 	page.FileProvider
+	page.GetPageProvider
+	page.InSectionPositioner
+	page.InternalDependencies
 	page.OutputFormatsProvider
 	page.PageMetaProvider
+	page.Positioner
+	page.RefProvider
+	page.RelatedKeywordsProvider
+	page.ShortcodeInfoProvider
 	page.SitesProvider
 	page.TreeProvider
+	navigation.PageMenusProvider
 	resource.LanguageProvider
 	resource.ResourceMetaProvider
 	resource.ResourceParamsProvider
@@ -42,6 +67,9 @@ type pageCommon struct {
 	// The parsed page content.
 	pageContent
 
+	// Keeps track of the shortcodes on a page.
+	shortcodeState *shortcodeHandler
+
 	// Any bundled resources
 	resources            resource.Resources
 	resourcesInit        sync.Once
@@ -57,6 +85,12 @@ type pageCommon struct {
 	// Will only be set for bundled pages.
 	parent *pageState
 
+	pageMenus *pageMenus
+
+	// Positional navigation
+	posNextPrev        *nextPrev
+	posNextPrevSection *nextPrev
+
 	// Set in fast render mode to force render a given page.
 	forceRender bool
 }
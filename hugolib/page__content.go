@@ -61,8 +61,17 @@ func (p *pageContentMap) AddBytes(item pageparser.Item) {
 	p.items = append(p.items, item)
 }
 
+func (p *pageContentMap) AddShortcode(s *shortcode) {
+	p.items = append(p.items, s)
+	if s.insertPlaceholder() {
+		p.hasNonMarkdownShortcode = true
+	} else {
+		p.hasMarkdownShortcode = true
+	}
+}
+
 // returns the content to be processed by Goldmark or similar.
-func (p pageContent) contentToRender(parsed pageparser.Result, pm *pageContentMap) []byte {
+func (p pageContent) contentToRender(parsed pageparser.Result, pm *pageContentMap, renderedShortcodes map[string]string) []byte {
 	source := parsed.Input()
 
 	c := make([]byte, 0, len(source)+(len(source)/10))
@@ -73,6 +82,22 @@ func (p pageContent) contentToRender(parsed pageparser.Result, pm *pageContentMa
 			c = append(c, source[v.Pos():v.Pos()+len(v.Val(source))]...)
 		case pageContentReplacement:
 			c = append(c, v.val...)
+		case *shortcode:
+			if !v.insertPlaceholder() {
+				// Insert the rendered shortcode.
+				renderedShortcode, found := renderedShortcodes[v.placeholder]
+				if !found {
+					// This should never happen.
+					panic(fmt.Sprintf("rendered shortcode %q not found", v.placeholder))
+				}
+
+				c = append(c, []byte(renderedShortcode)...)
+
+			} else {
+				// Insert the placeholder so we can insert the content after
+				// markdown processing.
+				c = append(c, []byte(v.placeholder)...)
+			}
 		default:
 			panic(fmt.Sprintf("unknown item type %T", it))
 		}
@@ -18,6 +18,7 @@ import (
 	"github.com/spf13/cast"
 	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/helpers"
+	"github.com/sunwei/hugo-playground/langs"
 	"github.com/sunwei/hugo-playground/markup/converter"
 	"github.com/sunwei/hugo-playground/output"
 	"github.com/sunwei/hugo-playground/related"
@@ -29,6 +30,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 )
 
 var cjkRe = regexp.MustCompile(`\p{Han}|\p{Hangul}|\p{Hiragana}|\p{Katakana}`)
@@ -208,6 +210,10 @@ func (p *pageMeta) Slug() string {
 	return p.urlPaths.Slug
 }
 
+func (p *pageMeta) Lang() string {
+	return p.s.Lang()
+}
+
 func (p *pageMeta) IsSection() bool {
 	return p.Kind() == page.KindSection
 }
@@ -301,7 +307,31 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 		frontmatter = make(map[string]any)
 	}
 
-	var err error
+	var mtime time.Time
+	var contentBaseName string
+	if !p.File().IsZero() {
+		contentBaseName = p.File().ContentBaseName()
+		if p.File().FileInfo() != nil {
+			mtime = p.File().FileInfo().ModTime()
+		}
+	}
+
+	descriptor := &pagemeta.FrontMatterDescriptor{
+		Frontmatter:  frontmatter,
+		Params:       pm.params,
+		Dates:        &pm.Dates,
+		PageURLs:     &pm.urlPaths,
+		BaseFilename: contentBaseName,
+		ModTime:      mtime,
+		Location:     langs.GetLocation(pm.s.Language()),
+	}
+
+	// Handle the date separately
+	err := pm.s.frontmatterHandler.HandleDates(descriptor)
+	if err != nil {
+		p.s.Log.Errorf("Failed to handle dates for page %q: %s", p.pathOrTitle(), err)
+	}
+
 	pm.buildConfig, err = pagemeta.DecodeBuildConfig(frontmatter["_build"]) // defaultBuildConfig
 	if err != nil {
 		return err
@@ -310,10 +340,104 @@ func (pm *pageMeta) setMetadata(parentBucket *pagesMapBucket, p *pageState, fron
 	for k, v := range frontmatter { // map[title:P1]
 		loki := strings.ToLower(k)
 
+		if pm.s.frontmatterHandler.IsDateKey(loki) {
+			continue
+		}
+
 		switch loki {
 		case "title":
 			pm.title = cast.ToString(v)
 			pm.params[loki] = pm.title
+		case "linktitle":
+			pm.linkTitle = cast.ToString(v)
+			pm.params[loki] = pm.linkTitle
+		case "description":
+			pm.description = cast.ToString(v)
+			pm.params[loki] = pm.description
+		case "slug":
+			// Don't start or end with a -
+			pm.urlPaths.Slug = strings.Trim(cast.ToString(v), "-")
+			pm.params[loki] = pm.Slug()
+		case "url":
+			url := cast.ToString(v)
+			if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+				return fmt.Errorf("URLs with protocol (http*) not supported: %q. In page %q", url, p.pathOrTitle())
+			}
+			pm.urlPaths.URL = url
+			pm.params[loki] = url
+		case "type":
+			pm.contentType = cast.ToString(v)
+			pm.params[loki] = pm.contentType
+		case "layout":
+			pm.layout = cast.ToString(v)
+			pm.params[loki] = pm.layout
+		case "markup":
+			pm.markup = cast.ToString(v)
+			pm.params[loki] = pm.markup
+		case "weight":
+			pm.weight = cast.ToInt(v)
+			pm.params[loki] = pm.weight
+		case "keywords":
+			pm.keywords = cast.ToStringSlice(v)
+			pm.params[loki] = pm.keywords
+		case "translationkey":
+			pm.translationKey = cast.ToString(v)
+			pm.params[loki] = pm.translationKey
+		case "resources":
+			var resources []map[string]any
+			handled := true
+
+			switch vv := v.(type) {
+			case []map[any]any:
+				for _, vvv := range vv {
+					resources = append(resources, maps.ToStringMap(vvv))
+				}
+			case []map[string]any:
+				resources = append(resources, vv...)
+			case []any:
+				for _, vvv := range vv {
+					switch vvvv := vvv.(type) {
+					case map[any]any:
+						resources = append(resources, maps.ToStringMap(vvvv))
+					case map[string]any:
+						resources = append(resources, vvvv)
+					}
+				}
+			default:
+				handled = false
+			}
+
+			if handled {
+				pm.params[loki] = resources
+				pm.resourcesMetadata = resources
+				break
+			}
+			fallthrough
+
+		default:
+			// If not one of the explicit values, store in Params
+			switch vv := v.(type) {
+			case []any:
+				// Handle array of strings as well, so they can be
+				// used as related content keywords.
+				if len(vv) > 0 {
+					switch vv[0].(type) {
+					case map[any]any, map[string]any, []any:
+						pm.params[loki] = vv
+					default:
+						a := make([]string, len(vv))
+						for i, u := range vv {
+							a[i] = cast.ToString(u)
+						}
+
+						pm.params[loki] = a
+					}
+				} else {
+					pm.params[loki] = []string{}
+				}
+			default:
+				pm.params[loki] = vv
+			}
 		}
 	}
 
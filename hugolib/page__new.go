@@ -19,13 +19,20 @@ func newPageBase(metaProvider *pageMeta) (*pageState, error) {
 		pageOutput:                        nopPageOutput,
 		pageOutputTemplateVariationsState: atomic.NewUint32(0),
 		pageCommon: &pageCommon{
-			FileProvider:           metaProvider,
-			ResourceMetaProvider:   metaProvider,
-			ResourceParamsProvider: metaProvider,
-			PageMetaProvider:       metaProvider,
-			OutputFormatsProvider:  page.NopPage,
-			ResourceTypeProvider:   pageTypesProvider,
-			LanguageProvider:       s,
+			FileProvider:            metaProvider,
+			GetPageProvider:         page.NopPage,
+			InSectionPositioner:     page.NopPage,
+			InternalDependencies:    s,
+			ResourceMetaProvider:    metaProvider,
+			ResourceParamsProvider:  metaProvider,
+			PageMetaProvider:        metaProvider,
+			Positioner:              page.NopPage,
+			RelatedKeywordsProvider: metaProvider,
+			OutputFormatsProvider:   page.NopPage,
+			RefProvider:             page.NopPage,
+			ShortcodeInfoProvider:   page.NopPage,
+			ResourceTypeProvider:    pageTypesProvider,
+			LanguageProvider:        s,
 
 			init: lazy.New(),
 			m:    metaProvider,
@@ -33,9 +40,15 @@ func newPageBase(metaProvider *pageMeta) (*pageState, error) {
 		},
 	}
 
+	ps.shortcodeState = newShortcodeHandler(ps, ps.s)
+
 	ps.ChildCareProvider = ps
+	ps.GetPageProvider = pageSiteAdapter{s: s, p: ps}
+	ps.ShortcodeInfoProvider = ps
 	ps.TreeProvider = pageTree{p: ps}
 	ps.Eqer = ps
+	ps.pageMenus = &pageMenus{p: ps}
+	ps.PageMenusProvider = ps.pageMenus
 
 	return ps, nil
 }
@@ -14,7 +14,10 @@
 package hugolib
 
 import (
+	"github.com/sunwei/hugo-playground/helpers"
 	"github.com/sunwei/hugo-playground/resources/page"
+	"net/url"
+	"strings"
 )
 
 func newPagePaths(
@@ -97,8 +100,9 @@ func (l pagePaths) OutputFormats() page.OutputFormats {
 
 func createTargetPathDescriptor(s *Site, p page.Page, pm *pageMeta) (page.TargetPathDescriptor, error) {
 	var (
-		dir      string
-		baseName string
+		dir             string
+		baseName        string
+		contentBaseName string
 	)
 
 	d := s.Deps
@@ -106,16 +110,48 @@ func createTargetPathDescriptor(s *Site, p page.Page, pm *pageMeta) (page.Target
 	if !p.File().IsZero() {
 		dir = p.File().Dir()
 		baseName = p.File().TranslationBaseName()
+		contentBaseName = p.File().ContentBaseName()
+	}
+
+	if baseName != contentBaseName {
+		// A leaf bundle
+		dir = strings.TrimSuffix(dir, contentBaseName+helpers.FilePathSeparator)
+		baseName = contentBaseName
 	}
 
 	desc := page.TargetPathDescriptor{
 		PathSpec:    d.PathSpec,
 		Kind:        p.Kind(),
 		Sections:    p.SectionsEntries(),
+		UglyURLs:    s.Info.uglyURLs(p),
 		ForcePrefix: false,
 		Dir:         dir,
 		URL:         pm.urlPaths.URL,
-		BaseName:    baseName,
+	}
+
+	if pm.Slug() != "" {
+		desc.BaseName = pm.Slug()
+	} else {
+		desc.BaseName = baseName
+	}
+
+	// Pages in a non-default language, or in every language when
+	// defaultContentLanguageInSubdir is set, live below the language code.
+	desc.PrefixFilePath = d.PathSpec.GetLanguagePrefix()
+	desc.PrefixLink = desc.PrefixFilePath
+
+	// The home page and standalone pages such as 404 and robots.txt
+	// have no section to key the permalink patterns on.
+	if !p.IsHome() && !pm.standalone {
+		opath, err := d.ResourceSpec.Permalinks.Expand(p.Section(), p)
+		if err != nil {
+			return desc, err
+		}
+
+		if opath != "" {
+			opath, _ = url.QueryUnescape(opath)
+			desc.ExpandedPermalink = opath
+		}
 	}
 
 	return desc, nil
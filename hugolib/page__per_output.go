@@ -70,15 +70,22 @@ type pageContentOutput struct {
 	initMain  *lazy.Init
 	initPlain *lazy.Init
 
+	// Content state
+
+	workContent       []byte
+	dependencyTracker identity.Manager // Set in server mode.
+
+	// Temporary storage of placeholders mapped to their content.
+	// These are shortcodes etc. Some of these will need to be replaced
+	// after any markup is rendered, so they share a common prefix.
+	contentPlaceholders map[string]string
+
 	placeholdersEnabled     bool
 	placeholdersEnabledInit sync.Once
 
 	// Renders Markdown hooks.
 	renderHooks *renderHooks
 
-	workContent       []byte
-	dependencyTracker identity.Manager // Set in server mode.
-
 	// Content sections
 	content         template.HTML
 	summary         template.HTML
@@ -136,8 +143,21 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 			return err
 		}
 
+		log.Process("output initContent", "render shortcodes")
+		var hasShortcodeVariants bool
+
+		f := po.f
+		cp.contentPlaceholders, hasShortcodeVariants, err = p.shortcodeState.renderShortcodesForPage(p, f)
+		if err != nil {
+			return err
+		}
+
+		if hasShortcodeVariants {
+			p.pageOutputTemplateVariationsState.Store(2)
+		}
+
 		log.Process("output initContent", "content to render")
-		cp.workContent = p.contentToRender(p.source.parsed, p.cmap)
+		cp.workContent = p.contentToRender(p.source.parsed, p.cmap, cp.contentPlaceholders)
 
 		isHTML := cp.p.m.markup == "html"
 
@@ -165,6 +185,20 @@ func newPageContentOutput(p *pageState, po *pageOutput) (*pageContentOutput, err
 			}
 		}
 
+		if cp.placeholdersEnabled {
+			// ToC was accessed via .Page.TableOfContents in the shortcode,
+			// at a time when the ToC wasn't ready.
+			cp.contentPlaceholders[tocShortcodePlaceholder] = string(cp.tableOfContents)
+		}
+
+		if p.cmap.hasNonMarkdownShortcode || cp.placeholdersEnabled {
+			// There are one or more replacement tokens to be replaced.
+			cp.workContent, err = replaceShortcodeTokens(cp.workContent, cp.contentPlaceholders)
+			if err != nil {
+				return err
+			}
+		}
+
 		if cp.p.source.hasSummaryDivider {
 			if isHTML {
 				src := p.source.parsed.Input()
@@ -0,0 +1,49 @@
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestNextPrev(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/root.md --
+---
+title: "Root"
+date: 2018-01-01
+---
+-- content/blog/a.md --
+---
+title: "A"
+date: 2021-01-01
+---
+-- content/blog/b.md --
+---
+title: "B"
+date: 2022-01-01
+---
+-- content/blog/c.md --
+---
+title: "C"
+date: 2020-01-01
+---
+-- content/docs/d.md --
+---
+title: "D"
+date: 2019-01-01
+---
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|N:{{ with .Next }}{{ .Title }}{{ end }}|P:{{ with .Prev }}{{ .Title }}{{ end }}|NS:{{ with .NextInSection }}{{ .Title }}{{ end }}|PS:{{ with .PrevInSection }}{{ .Title }}{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/a/index.html", "SINGLE A|N:B|P:C|NS:B|PS:C")
+	b.AssertFileContent("blog/b/index.html", "SINGLE B|N:|P:A|NS:|PS:A")
+	b.AssertFileContent("blog/c/index.html", "SINGLE C|N:A|P:D|NS:A|PS:")
+	b.AssertFileContent("docs/d/index.html", "SINGLE D|N:C|P:Root|NS:|PS:")
+	b.AssertFileContent("root/index.html", "SINGLE Root|N:D|P:|NS:|PS:")
+}
@@ -50,7 +50,7 @@ func (p pageRef) decodeRefArgs(args map[string]any) (refArgs, *Site, error) {
 		}
 
 		if !found {
-			fmt.Printf(ra.Path, fmt.Sprintf("no site found with lang %q", ra.Lang), nil, text.Position{})
+			p.p.s.siteRefLinker.logNotFound(ra.Path, fmt.Sprintf("no site found with lang %q", ra.Lang), nil, text.Position{})
 			return ra, nil, nil
 		}
 	}
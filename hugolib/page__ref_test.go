@@ -0,0 +1,118 @@
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestRef(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+refLinksErrorLevel = "warning"
+refLinksNotFoundURL = "/404-ref"
+-- content/blog/post.md --
+---
+title: "Post Title"
+---
+## Sec One
+
+Abs: {{< ref "/blog/other.md" >}}
+Rel: {{< relref "other" >}}
+Anchor: {{< relref "#sec-one" >}}
+PageAnchor: {{< relref "other.md#intro" >}}
+HTML: {{< relref path="other.md" outputFormat="html" >}}
+JSON: {{< relref path="other.md" outputFormat="json" >}}
+Missing: {{< relref "nope.md" >}}
+-- content/blog/other.md --
+---
+title: "Other"
+---
+-- layouts/index.html --
+HOME {{ ref . "/blog/post.md" }}|{{ relref . "blog/other" }}|{{ relref . (dict "path" "blog/other" "outputFormat" "html") }}
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .Content }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/post/index.html", `
+Abs: https://example.org/blog/other/
+Rel: /blog/other/
+Anchor: #sec-one
+PageAnchor: /blog/other/#intro
+HTML: /blog/other/
+JSON: /404-ref
+Missing: /404-ref
+`)
+	b.AssertFileContent("index.html", "HOME https://example.org/blog/post/|/blog/other/|/blog/other/")
+	b.AssertLogContains(`REF_NOT_FOUND: Ref "other.md" from page "blog/post.md": output format "json"`)
+	b.AssertLogContains(`REF_NOT_FOUND: Ref "nope.md"`)
+}
+
+func TestRefNotFoundIsError(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/p.md --
+---
+title: "P"
+---
+{{< relref "nope.md" >}}
+-- layouts/_default/single.html --
+{{ .Content }}
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files})
+	if err := b.BuildE(); err == nil {
+		t.Fatal("expected the missing ref to fail the build")
+	}
+	b.AssertLogContains(`REF_NOT_FOUND: Ref "nope.md"`)
+}
+
+func TestRefLang(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+refLinksErrorLevel = "warning"
+refLinksNotFoundURL = "/404-ref"
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+-- content/blog/post.md --
+---
+title: "Post"
+---
+FR: {{< relref path="blog/other" lang="fr" >}}
+ABSFR: {{< ref path="/blog/other.md" lang="fr" >}}
+EN: {{< relref "blog/other" >}}
+DE: {{< relref path="blog/other" lang="de" >}}
+-- content/blog/other.md --
+---
+title: "Other"
+---
+-- content/blog/other.fr.md --
+---
+title: "Autre"
+---
+-- layouts/index.html --
+HOME {{ .Lang }}|{{ relref . (dict "path" "blog/other" "lang" "en") }}|{{ relref . "/blog/other.md" }}
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .Content }}
+-- layouts/_default/list.html --
+LIST
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/post/index.html", `
+FR: /fr/blog/other/
+ABSFR: https://example.org/fr/blog/other/
+EN: /blog/other/
+DE: /404-ref
+`)
+	b.AssertFileContent("fr/index.html", "HOME fr|/blog/other/|/fr/blog/other/")
+	b.AssertLogContains(`REF_NOT_FOUND: Ref "blog/other": no site found with lang "de"`)
+}
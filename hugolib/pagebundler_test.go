@@ -0,0 +1,100 @@
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestPageBundlerResources(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/blog/_index.md --
+---
+title: "Blog"
+---
+-- content/blog/banner.jpg --
+JPGDATA
+-- content/blog/post/index.md --
+---
+title: "Post"
+---
+Hello
+-- content/blog/post/a.png --
+PNGDATA
+-- content/blog/post/data.json --
+{"a": 1}
+-- content/blog/post/notes.txt --
+notes
+-- content/blog/post/extra.md --
+---
+title: "Extra"
+---
+Extra content
+-- content/blog/other.md --
+---
+title: "Other"
+---
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ len .Resources }}
+{{ range .Resources.ByType "image" }}IMG {{ .Name }}|{{ .RelPermalink }}|{{ .MediaType }}|{{ .ResourceType }}
+{{ end }}{{ with .Resources.Get "data.json" }}GET {{ .Name }}|{{ .MediaType }}
+{{ end }}{{ with .Resources.GetMatch "*.txt" }}GM {{ .Name }}|{{ .Permalink }}
+{{ end }}{{ range .Resources.Match "*" }}M {{ .Name }}|{{ .ResourceType }}
+{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}|{{ len .Resources }}|{{ range .Resources }}{{ .Name }} {{ .RelPermalink }}{{ end }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/post/index.html", `
+SINGLE Post|4
+IMG a.png|/blog/post/a.png|image/png|image
+GET data.json|application/json
+GM notes.txt|https://example.org/blog/post/notes.txt
+M data.json|application
+M a.png|image
+M notes.txt|text
+M extra.md|page
+`)
+	b.AssertFileContent("blog/index.html", "LIST Blog|1|banner.jpg /blog/banner.jpg")
+	b.AssertFileContent("blog/other/index.html", "SINGLE Other|0")
+
+	// Bundle files are published on first permalink access only.
+	b.AssertFileContent("blog/post/a.png", "PNGDATA")
+	b.AssertFileContent("blog/post/notes.txt", "notes")
+	b.AssertFileContent("blog/banner.jpg", "JPGDATA")
+	b.AssertFileExists("blog/post/data.json", false)
+	b.AssertFileExists("blog/post/extra/index.html", false)
+}
+
+func TestPageBundlerMultilingual(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+-- content/post/index.md --
+---
+title: "Post"
+---
+-- content/post/extra.fr.md --
+---
+title: "Extra FR"
+---
+-- content/post/a.png --
+PNG
+-- layouts/_default/single.html --
+SINGLE {{ .Lang }} {{ .Title }}|{{ range .Resources }}{{ .Name }}:{{ .RelPermalink }},{{ end }}
+-- layouts/_default/list.html --
+LIST
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("post/index.html", "SINGLE en Post|a.png:/post/a.png,")
+	b.AssertFileContent("fr/post/index.html", "SINGLE fr Post|a.png:/fr/post/a.png,extra.fr.md:,")
+	b.AssertFileContent("fr/post/a.png", "PNG")
+}
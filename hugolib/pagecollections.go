@@ -1,34 +1,65 @@
 package hugolib
 
 import (
+	"fmt"
+	"github.com/sunwei/hugo-playground/common/paths"
+	"github.com/sunwei/hugo-playground/hugofs/files"
 	"github.com/sunwei/hugo-playground/resources/page"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // PageCollections contains the page collections for a site.
 type PageCollections struct {
 	pageMap *pageMap
+
+	// Lazy initialized page collections
+	pages           *lazyPagesFactory
+	regularPages    *lazyPagesFactory
+	allPages        *lazyPagesFactory
+	allRegularPages *lazyPagesFactory
 }
 
 // Pages returns all pages.
 // This is for the current language only.
 func (c *PageCollections) Pages() page.Pages {
-	panic("page collections Pages not implemented")
+	return c.pages.get()
 }
 
 // RegularPages returns all the regular pages.
 // This is for the current language only.
 func (c *PageCollections) RegularPages() page.Pages {
-	panic("page collections not implement RegularPages")
+	return c.regularPages.get()
 }
 
 // AllPages returns all pages for all languages.
 func (c *PageCollections) AllPages() page.Pages {
-	panic("page collections not implement AllPages")
+	return c.allPages.get()
 }
 
 // AllRegularPages AllPages returns all regular pages for all languages.
 func (c *PageCollections) AllRegularPages() page.Pages {
-	panic("page collections not implement AllRegularPages")
+	return c.allRegularPages.get()
+}
+
+type lazyPagesFactory struct {
+	pages page.Pages
+
+	init    sync.Once
+	factory page.PagesFactory
+}
+
+func (l *lazyPagesFactory) get() page.Pages {
+	l.init.Do(func() {
+		l.pages = l.factory()
+	})
+	return l.pages
+}
+
+func newLazyPagesFactory(factory page.PagesFactory) *lazyPagesFactory {
+	return &lazyPagesFactory{factory: factory}
 }
 
 func newPageCollections(m *pageMap) *PageCollections {
@@ -38,9 +69,210 @@ func newPageCollections(m *pageMap) *PageCollections {
 
 	c := &PageCollections{pageMap: m}
 
+	c.pages = newLazyPagesFactory(func() page.Pages {
+		return m.createListAllPages()
+	})
+
+	c.regularPages = newLazyPagesFactory(func() page.Pages {
+		return c.findPagesByKindIn(page.KindPage, c.pages.get())
+	})
+
 	return c
 }
 
+// This is an adapter func for the old API with Kind as first argument.
+// This is invoked when you do .Site.GetPage. We drop the Kind and fails
+// if there are more than 2 arguments, which would be ambiguous.
+func (c *PageCollections) getPageOldVersion(ref ...string) (page.Page, error) {
+	var refs []string
+	for _, r := range ref {
+		// A common construct in the wild is
+		// .Site.GetPage "home" "" or
+		// .Site.GetPage "home" "/"
+		if r != "" && r != "/" {
+			refs = append(refs, r)
+		}
+	}
+
+	var key string
+
+	if len(refs) > 2 {
+		// This was allowed in Hugo <= 0.44, but we cannot support this with the
+		// new API. This should be the most unusual case.
+		return nil, fmt.Errorf(`too many arguments to .Site.GetPage: %v. Use lookups on the form {{ .Site.GetPage "/posts/mypage-md" }}`, ref)
+	}
+
+	if len(refs) == 0 || refs[0] == page.KindHome {
+		key = "/"
+	} else if len(refs) == 1 {
+		if len(ref) == 2 && refs[0] == page.KindSection {
+			// This is an old style reference to the "Home Page section".
+			// Typically fetched via {{ .Site.GetPage "section" .Section }}
+			// See https://github.com/gohugoio/hugo/issues/4989
+			key = "/"
+		} else {
+			key = refs[0]
+		}
+	} else {
+		key = refs[1]
+	}
+
+	key = filepath.ToSlash(key)
+	if !strings.HasPrefix(key, "/") {
+		key = "/" + key
+	}
+
+	return c.getPageNew(nil, key)
+}
+
+func (c *PageCollections) getPageNew(context page.Page, ref string) (page.Page, error) {
+	n, err := c.getContentNode(context, false, ref)
+	if err != nil || n == nil || n.p == nil {
+		return nil, err
+	}
+	return n.p, nil
+}
+
+// getPageRef resolves a Page from ref/relRef, with a slightly more comprehensive
+// search path than getPageNew.
+func (c *PageCollections) getPageRef(context page.Page, ref string) (page.Page, error) {
+	n, err := c.getContentNode(context, true, ref)
+	if err != nil || n == nil || n.p == nil {
+		return nil, err
+	}
+	return n.p, nil
+}
+
+func (c *PageCollections) getSectionOrPage(ref string) (*contentNode, string) {
+	m := c.pageMap
+
+	// A section or a page by its logical path, e.g. /blog or /blog/post.
+	if n := m.pageIndex.GetPath(ref); n != nil {
+		return n, ""
+	}
+
+	langSuffix := "." + m.s.Lang()
+
+	// Trim both extension and any language code.
+	name := paths.PathNoExt(ref)
+	name = strings.TrimSuffix(name, langSuffix)
+
+	// These are reserved bundle names and will always be stored by their owning
+	// folder name.
+	name = strings.TrimSuffix(name, "/index")
+	name = strings.TrimSuffix(name, "/_index")
+
+	return m.pageIndex.GetPath(name), name
+}
+
+func (c *PageCollections) getContentNode(context page.Page, isReflink bool, ref string) (*contentNode, error) {
+	ref = filepath.ToSlash(strings.ToLower(strings.TrimSpace(ref)))
+
+	if ref == "" {
+		ref = "/"
+	}
+
+	inRef := ref
+	navUp := strings.HasPrefix(ref, "..")
+	var doSimpleLookup bool
+	if isReflink || context == nil {
+		doSimpleLookup = shouldDoSimpleLookup(ref)
+	}
+
+	if context != nil && !strings.HasPrefix(ref, "/") {
+		// Try the page-relative path.
+		var base string
+		if context.File().IsZero() {
+			base = context.SectionsPath()
+		} else {
+			meta := context.File().FileInfo().Meta()
+			base = filepath.ToSlash(filepath.Dir(meta.Path))
+			if meta.Classifier == files.ContentClassLeaf {
+				// Bundles are stored in subfolders e.g. blog/mybundle/index.md,
+				// so if the user has not explicitly asked to go up,
+				// look on the "blog" level.
+				if !navUp {
+					base = path.Dir(base)
+				}
+			}
+		}
+		ref = path.Join("/", strings.ToLower(base), ref)
+	}
+
+	if !strings.HasPrefix(ref, "/") {
+		ref = "/" + ref
+	}
+
+	m := c.pageMap
+
+	// It's either a section or a page in a section.
+	n, name := c.getSectionOrPage(ref)
+	if n != nil {
+		return n, nil
+	}
+
+	if !strings.HasPrefix(inRef, "/") {
+		// Many people will have "post/foo.md" in their content files.
+		if n, _ := c.getSectionOrPage("/" + inRef); n != nil {
+			return n, nil
+		}
+	}
+
+	getByName := func(s string) (*contentNode, error) {
+		n := m.pageIndex.GetReverse(s)
+		if n != nil {
+			if n == ambiguousContentNode {
+				return nil, fmt.Errorf("page reference %q is ambiguous", ref)
+			}
+			return n, nil
+		}
+
+		return nil, nil
+	}
+
+	var module string
+	if context != nil && !context.File().IsZero() {
+		module = context.File().FileInfo().Meta().Module
+	}
+
+	if module == "" && m.s.home != nil && !m.s.home.File().IsZero() {
+		module = m.s.home.File().FileInfo().Meta().Module
+	}
+
+	if module != "" {
+		n, err := getByName(module + ref)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			return n, nil
+		}
+	}
+
+	if !doSimpleLookup {
+		return nil, nil
+	}
+
+	// Ref/relref supports this potentially ambigous lookup.
+	return getByName(path.Base(name))
+}
+
+// For Ref/Reflink and .Site.GetPage do simple name lookups for the potentially ambigous myarticle.md and /myarticle.md,
+// but not when we get ./myarticle*, section/myarticle.
+func shouldDoSimpleLookup(ref string) bool {
+	if ref[0] == '.' {
+		return false
+	}
+
+	slashCount := strings.Count(ref, "/")
+
+	if slashCount > 1 {
+		return false
+	}
+
+	return slashCount == 0 || ref[0] == '/'
+}
+
 func (*PageCollections) findPagesByKindIn(kind string, inPages page.Pages) page.Pages {
 	var pages page.Pages
 	for _, p := range inPages {
@@ -0,0 +1,98 @@
+package hugolib
+
+import (
+	"strings"
+	"testing"
+)
+
+const getPageFiles = `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/blog/_index.md --
+---
+title: "The Blog"
+---
+-- content/blog/post.md --
+---
+title: "Blog Post"
+---
+-- content/blog/other.md --
+---
+title: "Other"
+---
+-- content/blog/mybundle/index.md --
+---
+title: "My Bundle"
+---
+-- content/docs/post.md --
+---
+title: "Docs Post"
+---
+-- content/docs/unique.md --
+---
+title: "Unique"
+---
+-- content/docs/a/b/deep.md --
+---
+title: "Deep"
+---
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|REL:{{ with .GetPage "other" }}{{ .Title }}{{ end }}|UP:{{ with .GetPage "../docs/unique" }}{{ .Title }}{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+`
+
+func TestGetPage(t *testing.T) {
+	files := getPageFiles + `
+-- layouts/index.html --
+HOME
+A:{{ with .Site.GetPage "/blog/post" }}{{ .Title }}{{ end }}
+B:{{ with .Site.GetPage "blog" }}{{ .Kind }} {{ .Title }}{{ end }}
+C:{{ with .Site.GetPage "blog/post.md" }}{{ .Title }}{{ end }}
+D:{{ with .Site.GetPage "section" "docs" }}{{ .Kind }}{{ end }}
+E:{{ with .Site.GetPage "unique.md" }}{{ .Title }}{{ end }}
+F:{{ with .Site.GetPage "/docs/unique.md" }}{{ .Title }}{{ end }}
+G:{{ with .GetPage "blog/other" }}{{ .Title }}{{ end }}
+H:{{ with .Site.GetPage "home" }}{{ .Kind }}{{ end }}
+I:{{ (.Site.GetPage "nope").IsPage }}
+J:{{ with .Site.GetPage "/blog/_index.md" }}{{ .Title }}{{ end }}
+K:{{ with .Site.GetPage "/blog/mybundle" }}{{ .Title }}{{ end }}
+L:{{ with .Site.GetPage "/blog/mybundle/index.md" }}{{ .Title }}{{ end }}
+M:{{ with .Site.GetPage "/docs/a/b/deep" }}{{ .Title }}{{ end }}
+N:{{ with .Site.GetPage "/BLOG/Post/" }}{{ .Title }}{{ end }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("index.html", `
+A:Blog Post
+B:section The Blog
+C:Blog Post
+D:section
+E:Unique
+F:Unique
+G:Other
+H:home
+I:false
+J:The Blog
+K:My Bundle
+L:My Bundle
+M:Deep
+N:Blog Post
+`)
+	b.AssertFileContent("blog/post/index.html", "SINGLE Blog Post|REL:Other|UP:Unique")
+	b.AssertFileContent("blog/mybundle/index.html", "SINGLE My Bundle|REL:Other|")
+}
+
+func TestGetPageAmbiguous(t *testing.T) {
+	files := getPageFiles + `
+-- layouts/index.html --
+{{ with .Site.GetPage "post.md" }}{{ .Title }}{{ end }}
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil || !strings.Contains(err.Error(), `page reference "/post.md" is ambiguous`) {
+		t.Fatalf("expected ambiguous page reference error, got %v", err)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/spf13/afero"
+	"github.com/sunwei/hugo-playground/common/loggers"
 	"github.com/sunwei/hugo-playground/hugofs"
 	"github.com/sunwei/hugo-playground/hugofs/files"
 	"github.com/sunwei/hugo-playground/source"
 	"os"
+	pth "path"
 	"path/filepath"
 )
 
@@ -19,10 +21,12 @@ type fileinfoBundle struct {
 func newPagesCollector(
 	sp *source.SourceSpec,
 	contentMap *pageMaps,
+	logger loggers.Logger,
 	proc pagesCollectorProcessorProvider, filenames ...string) *pagesCollector {
 
 	return &pagesCollector{
 		fs:         sp.SourceFs,
+		logger:     logger,
 		contentMap: contentMap,
 		proc:       proc,
 		sp:         sp,
@@ -31,8 +35,9 @@ func newPagesCollector(
 }
 
 type pagesCollector struct {
-	sp *source.SourceSpec
-	fs afero.Fs
+	sp     *source.SourceSpec
+	fs     afero.Fs
+	logger loggers.Logger
 
 	contentMap *pageMaps
 
@@ -91,6 +96,19 @@ func (c *pagesCollector) collectDir(dirname string) error {
 		dir hugofs.FileMetaInfo,
 		path string,
 		readdir []hugofs.FileMetaInfo) error {
+		if btype == bundleBranch {
+			if err := c.handleBundleBranch(readdir); err != nil {
+				return err
+			}
+			// A branch bundle is only this directory level, so keep walking.
+			return nil
+		} else if btype == bundleLeaf {
+			if err := c.handleBundleLeaf(dir, path, readdir); err != nil {
+				return err
+			}
+
+			return nil
+		}
 
 		if err := c.handleFiles(readdir...); err != nil {
 			return err
@@ -120,8 +138,63 @@ func (c *pagesCollector) collectDir(dirname string) error {
 				filtered = append(filtered, fi)
 			}
 		}
+		walkRoot := dir.Meta().IsRootFile
 		readdir = filtered
 
+		// We merge language directories, so there can be duplicates, but they
+		// will be ordered, most important first.
+		var duplicates []int
+		seen := make(map[string]bool)
+
+		for i, fi := range readdir {
+
+			if fi.IsDir() {
+				continue
+			}
+
+			meta := fi.Meta()
+			meta.IsRootFile = walkRoot
+			class := meta.Classifier
+			translationBase := meta.TranslationBaseNameWithExt
+			key := pth.Join(meta.Lang, translationBase)
+
+			if seen[key] {
+				duplicates = append(duplicates, i)
+				continue
+			}
+			seen[key] = true
+
+			var thisBtype bundleDirType
+
+			switch class {
+			case files.ContentClassLeaf:
+				thisBtype = bundleLeaf
+			case files.ContentClassBranch:
+				thisBtype = bundleBranch
+			}
+
+			// Folders with both index.md and _index.md type of files have
+			// undefined behaviour and can never work.
+			// The branch variant will win because of sort order, but log
+			// a warning about it.
+			if thisBtype > bundleNot && btype > bundleNot && thisBtype != btype {
+				c.logger.Warnf("Content directory %q have both index.* and _index.* files, pick one.", dir.Meta().Filename)
+				// Reclassify it so it will be handled as a content file inside the
+				// section, which is in line with the <= 0.55 behaviour.
+				meta.Classifier = files.ContentClassContent
+			} else if thisBtype > bundleNot {
+				btype = thisBtype
+			}
+
+		}
+
+		if len(duplicates) > 0 {
+			for i := len(duplicates) - 1; i >= 0; i-- {
+				idx := duplicates[i]
+				readdir = append(readdir[:idx], readdir[idx+1:]...)
+			}
+		}
+
 		err := handleDir(btype, dir, path, readdir)
 		if err != nil {
 			return nil, err
@@ -150,6 +223,7 @@ func (c *pagesCollector) collectDir(dirname string) error {
 
 	w := hugofs.NewWalkway(hugofs.WalkwayConfig{
 		Fs:       c.fs,
+		Logger:   c.logger,
 		Root:     dirname,
 		Info:     fim,
 		HookPre:  preHook,
@@ -194,6 +268,38 @@ func (c *pagesCollector) handleBundleBranch(readdir []hugofs.FileMetaInfo) error
 	return c.handleFiles(contentFiles...)
 }
 
+func (c *pagesCollector) handleBundleLeaf(dir hugofs.FileMetaInfo, path string, readdir []hugofs.FileMetaInfo) error {
+	// Maps bundles to its language.
+	bundles := pageBundles{}
+
+	walk := func(path string, info hugofs.FileMetaInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		return c.addToBundle(info, bundleLeaf, bundles)
+	}
+
+	// Start a new walker from the given path.
+	w := hugofs.NewWalkway(hugofs.WalkwayConfig{
+		Root:       path,
+		Fs:         c.fs,
+		Logger:     c.logger,
+		Info:       dir,
+		DirEntries: readdir,
+		WalkFn:     walk,
+	})
+
+	if err := w.Walk(); err != nil {
+		return err
+	}
+
+	return c.proc.Process(bundles)
+}
+
 func (c *pagesCollector) addToBundle(info hugofs.FileMetaInfo, btyp bundleDirType, bundles pageBundles) error {
 	getBundle := func(lang string) *fileinfoBundle {
 		return bundles[lang]
@@ -221,6 +327,7 @@ func (c *pagesCollector) addToBundle(info hugofs.FileMetaInfo, btyp bundleDirTyp
 		}
 
 		clone := c.cloneFileInfo(source.header)
+		clone.Meta().Lang = lang
 
 		return &fileinfoBundle{
 			header: clone,
@@ -281,6 +388,11 @@ func (c *pagesCollector) cloneFileInfo(fi hugofs.FileMetaInfo) hugofs.FileMetaIn
 }
 
 func (c *pagesCollector) getLang(fi hugofs.FileMetaInfo) string {
+	lang := fi.Meta().Lang
+	if lang != "" {
+		return lang
+	}
+
 	return c.sp.DefaultContentLanguage
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/sunwei/hugo-playground/hugofs/files"
 	"github.com/sunwei/hugo-playground/source"
 	"golang.org/x/sync/errgroup"
+	"path/filepath"
 )
 
 func newPagesProcessor(h *HugoSites, sp *source.SourceSpec) *pagesProcessor {
@@ -106,9 +107,29 @@ func (p *sitePagesProcessor) Wait() error {
 	return p.itemGroup.Wait()
 }
 
+func (p *sitePagesProcessor) copyFile(fim hugofs.FileMetaInfo) error {
+	meta := fim.Meta()
+	f, err := meta.Open()
+	if err != nil {
+		return fmt.Errorf("copyFile: failed to open: %w", err)
+	}
+
+	s := p.m.s
+
+	target := filepath.Join(s.PathSpec.GetTargetLanguageBasePath(), meta.Path)
+
+	defer f.Close()
+
+	return s.publish(target, f, s.BaseFs.PublishFs)
+}
+
 func (p *sitePagesProcessor) doProcess(item any) error {
 	m := p.m
 	switch v := item.(type) {
+	case *fileinfoBundle:
+		if err := m.AddFilesBundle(v.header, v.resources...); err != nil {
+			return err
+		}
 	case hugofs.FileMetaInfo:
 		meta := v.Meta()
 
@@ -119,7 +140,9 @@ func (p *sitePagesProcessor) doProcess(item any) error {
 				return err
 			}
 		case files.ContentClassFile:
-			panic("doProcess not support ContentClassFile yet")
+			if err := p.copyFile(v); err != nil {
+				return err
+			}
 		default:
 			panic(fmt.Sprintf("invalid classifier: %q", classifier))
 		}
@@ -132,7 +155,7 @@ func (p *sitePagesProcessor) doProcess(item any) error {
 var defaultPageProcessor = new(nopPageProcessor)
 
 func (proc *pagesProcessor) getProcFromFi(fi hugofs.FileMetaInfo) pagesCollectorProcessorProvider {
-	if p, found := proc.procs["en"]; found {
+	if p, found := proc.procs[fi.Meta().Lang]; found {
 		return p
 	}
 	return defaultPageProcessor
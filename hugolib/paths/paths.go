@@ -17,7 +17,9 @@ import (
 	"fmt"
 	"github.com/sunwei/hugo-playground/config"
 	"github.com/sunwei/hugo-playground/hugofs"
+	"github.com/sunwei/hugo-playground/hugofs/files"
 	"github.com/sunwei/hugo-playground/langs"
+	"github.com/sunwei/hugo-playground/markup"
 	"github.com/sunwei/hugo-playground/modules"
 	"path/filepath"
 	"strings"
@@ -32,7 +34,8 @@ type Paths struct {
 	Cfg config.Provider
 
 	BaseURL
-	BaseURLString string
+	BaseURLString       string
+	BaseURLNoPathString string
 
 	// If the baseURL contains a base path, e.g. https://example.com/docs, then "/docs" will be the BasePath.
 	BasePath string
@@ -51,10 +54,24 @@ type Paths struct {
 	// pagination path handling
 	PaginatePath string
 
+	DisablePathToLower bool
+	RemovePathAccents  bool
+	CanonifyURLs       bool
+
 	Language              *langs.Language
 	Languages             langs.Languages
 	LanguagesDefaultFirst langs.Languages
 
+	// The file extensions of content files: the built-in ones, those set in
+	// contentFileExtensions and the aliases of any registered converters.
+	ContentFileExtensions files.ContentFileExtensions
+
+	// Some settings, the settings listed below, do not make sense to be set
+	// on per-language-basis, so we pick them up once here.
+	defaultContentLanguageInSubdir bool
+	DefaultContentLanguage         string
+	multilingual                   bool
+
 	AllModules modules.Modules
 }
 
@@ -89,13 +106,35 @@ func New(fs *hugofs.Fs, cfg config.Provider) (*Paths, error) {
 		absResourcesDir = FilePathSeparator
 	}
 
+	var (
+		language              *langs.Language
+		languages             langs.Languages
+		languagesDefaultFirst langs.Languages
+	)
+
+	if l, ok := cfg.(*langs.Language); ok {
+		language = l
+	}
+
+	if l, ok := cfg.Get("languagesSorted").(langs.Languages); ok {
+		languages = l
+	}
+
+	if l, ok := cfg.Get("languagesSortedDefaultFirst").(langs.Languages); ok {
+		languagesDefaultFirst = l
+	}
+
 	var baseURLString = baseURL.String()
+	var baseURLNoPath = baseURL.URL()
+	baseURLNoPath.Path = ""
+	var baseURLNoPathString = baseURLNoPath.String()
 
 	p := &Paths{
-		Fs:            fs,
-		Cfg:           cfg,
-		BaseURL:       baseURL,
-		BaseURLString: baseURLString,
+		Fs:                  fs,
+		Cfg:                 cfg,
+		BaseURL:             baseURL,
+		BaseURLString:       baseURLString,
+		BaseURLNoPathString: baseURLNoPathString,
 
 		ThemesDir:  cfg.GetString("themesDir"),
 		WorkingDir: workingDir,
@@ -104,6 +143,21 @@ func New(fs *hugofs.Fs, cfg config.Provider) (*Paths, error) {
 		AbsPublishDir:   absPublishDir,
 
 		PaginatePath: cfg.GetString("paginatePath"),
+
+		DisablePathToLower: cfg.GetBool("disablePathToLower"),
+		RemovePathAccents:  cfg.GetBool("removePathAccents"),
+		CanonifyURLs:       cfg.GetBool("canonifyURLs"),
+
+		multilingual:                   cfg.GetBool("multilingual"),
+		defaultContentLanguageInSubdir: cfg.GetBool("defaultContentLanguageInSubdir"),
+		DefaultContentLanguage:         cfg.GetString("defaultContentLanguage"),
+
+		Language:              language,
+		Languages:             languages,
+		LanguagesDefaultFirst: languagesDefaultFirst,
+
+		ContentFileExtensions: files.NewContentFileExtensions(
+			append(cfg.GetStringSlice("contentFileExtensions"), markup.RegisteredAliases()...)...),
 	}
 
 	if cfg.IsSet("allModules") {
@@ -114,19 +168,44 @@ func New(fs *hugofs.Fs, cfg config.Provider) (*Paths, error) {
 }
 
 func (p *Paths) Lang() string {
-	return "en"
+	if p == nil || p.Language == nil {
+		return ""
+	}
+	return p.Language.Lang
 }
 
 func (p *Paths) GetTargetLanguageBasePath() string {
+	if p.Languages.IsMultihost() {
+		// In a multihost configuration all assets will be published below the language code.
+		return p.Lang()
+	}
 	return p.GetLanguagePrefix()
 }
 
+// GetLanguagePrefix returns the language path prefix to use in URLs for the
+// current language, which is empty unless the site is multilingual and the
+// language is not the default one (or defaultContentLanguageInSubdir is set).
 func (p *Paths) GetLanguagePrefix() string {
-	return "en"
+	if !p.multilingual {
+		return ""
+	}
+
+	defaultLang := p.DefaultContentLanguage
+	defaultInSubDir := p.defaultContentLanguageInSubdir
+
+	currentLang := p.Lang()
+	if currentLang == "" || (currentLang == defaultLang && !defaultInSubDir) {
+		return ""
+	}
+	return currentLang
 }
 
 // GetBasePath returns any path element in baseURL if needed.
 func (p *Paths) GetBasePath(isRelativeURL bool) string {
+	if isRelativeURL && p.CanonifyURLs {
+		// The baseURL will be prepended later.
+		return ""
+	}
 	return p.BasePath
 }
 
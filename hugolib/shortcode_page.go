@@ -2,8 +2,45 @@ package hugolib
 
 import (
 	"github.com/sunwei/hugo-playground/resources/page"
+	"html/template"
 )
 
+var tocShortcodePlaceholder = createShortcodePlaceholder("TOC", 0)
+
+// This is sent to the shortcodes. They cannot access the content
+// they're a part of. It would cause an infinite regress.
+//
+// Go doesn't support virtual methods, so this careful dance is currently (I think)
+// the best we can do.
+type pageForShortcode struct {
+	page.PageWithoutContent
+	page.ContentProvider
+
+	// We need to replace it after we have rendered it, so provide a
+	// temporary placeholder.
+	toc template.HTML
+
+	p *pageState
+}
+
+func newPageForShortcode(p *pageState) page.Page {
+	return &pageForShortcode{
+		PageWithoutContent: p,
+		ContentProvider:    page.NopPage,
+		toc:                template.HTML(tocShortcodePlaceholder),
+		p:                  p,
+	}
+}
+
+func (p *pageForShortcode) page() page.Page {
+	return p.PageWithoutContent.(page.Page)
+}
+
+func (p *pageForShortcode) TableOfContents() template.HTML {
+	p.p.enablePlaceholders()
+	return p.toc
+}
+
 func newPageForRenderHook(p *pageState) page.Page {
 	return &pageForRenderHooks{
 		PageWithoutContent:      p,
@@ -0,0 +1,73 @@
+package hugolib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShortcodes(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/blog/post.md --
+---
+title: "Post Title"
+---
+{{< hello name="World" >}}
+{{< pos "a" "b" >}}
+{{% md %}}**bold**{{% /md %}}
+{{< wrap >}}inner text{{< /wrap >}}
+{{< wrap >}}{{< hello name="Nested" >}}{{< /wrap >}}
+{{</* hello name="Escaped" */>}}
+-- content/blog/other.md --
+---
+title: "Other"
+---
+No shortcodes.
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .Content }}|HAS:{{ .HasShortcode "hello" }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+-- layouts/shortcodes/hello.html --
+Hello {{ .Get "name" }}!
+-- layouts/shortcodes/pos.html --
+POS {{ .Get 0 }}-{{ .Get 1 }}|{{ .IsNamedParams }}|{{ .Page.Title }}
+-- layouts/shortcodes/md.html --
+{{ .Inner }}
+-- layouts/shortcodes/wrap.html --
+<div>{{ .Inner }}</div>
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/post/index.html", `
+Hello World!
+POS a-b|false|Post Title
+<strong>bold</strong>
+<div>inner text</div>
+<div>Hello Nested!</div>
+{{&lt; hello name=&ldquo;Escaped&rdquo; &gt;}}
+|HAS:true
+`)
+	b.AssertFileContent("blog/other/index.html", "<p>No shortcodes.</p>", "|HAS:false")
+}
+
+func TestShortcodeNotFound(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/p.md --
+---
+title: "P"
+---
+{{< nope >}}
+-- layouts/_default/single.html --
+{{ .Content }}
+`
+
+	b := NewIntegrationTestBuilder(IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil || !strings.Contains(err.Error(), `content/p.md:4:1": failed to extract shortcode: template for shortcode "nope" not found`) {
+		t.Fatalf("expected shortcode not found error, got %v", err)
+	}
+}
@@ -3,9 +3,12 @@ package hugolib
 import (
 	"fmt"
 	"github.com/spf13/afero"
+	"github.com/spf13/cast"
 	bp "github.com/sunwei/hugo-playground/bufferpool"
+	"github.com/sunwei/hugo-playground/common/loggers"
 	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/common/text"
+	"github.com/sunwei/hugo-playground/common/types"
 	"github.com/sunwei/hugo-playground/config"
 	"github.com/sunwei/hugo-playground/deps"
 	"github.com/sunwei/hugo-playground/helpers"
@@ -15,13 +18,18 @@ import (
 	"github.com/sunwei/hugo-playground/log"
 	"github.com/sunwei/hugo-playground/markup/converter"
 	"github.com/sunwei/hugo-playground/media"
+	"github.com/sunwei/hugo-playground/navigation"
 	"github.com/sunwei/hugo-playground/output"
 	"github.com/sunwei/hugo-playground/publisher"
+	"github.com/sunwei/hugo-playground/related"
 	"github.com/sunwei/hugo-playground/resources/page"
+	"github.com/sunwei/hugo-playground/resources/page/pagemeta"
 	"github.com/sunwei/hugo-playground/source"
 	"github.com/sunwei/hugo-playground/tpl"
 	"html/template"
 	"io"
+	stdlog "log"
+	"net/url"
 	"path"
 	"path/filepath"
 	"sort"
@@ -69,6 +77,9 @@ type Site struct {
 	// The func used to title case titles.
 	titleFunc func(s string) string
 
+	// Maps front matter dates into the page dates.
+	frontmatterHandler pagemeta.FrontMatterHandler
+
 	// newSite with above infos
 
 	// The owning container. When multiple languages, there will be multiple
@@ -80,6 +91,13 @@ type Site struct {
 	Sections Taxonomy
 	Info     *SiteInfo
 
+	menus navigation.Menus
+
+	relatedDocsHandler *page.RelatedDocsHandler
+
+	// Lazily loaded site dependencies
+	init *siteInit
+
 	// The output formats that we need to render this site in. This slice
 	// will be fixed once set.
 	// This will be the union of Site.Pages' outputFormats.
@@ -105,13 +123,17 @@ type siteRenderingContext struct {
 type siteRefLinker struct {
 	s *Site
 
+	errorLogger *stdlog.Logger
 	notFoundURL string
 }
 
 type SiteInfo struct {
 	title string
 
-	relativeURLs bool
+	relativeURLs     bool
+	canonifyURLs     bool
+	uglyURLs         func(p page.Page) bool
+	sectionPagesMenu string
 
 	owner *HugoSites
 	s     *Site
@@ -128,6 +150,10 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		err                     error
 	)
 
+	if cfg.Logger == nil {
+		cfg.Logger = loggers.NewErrorLogger()
+	}
+
 	// [{toml}, {html}, {markdown}, {plain}]
 	log.Process("media.DecodeTypes", "set default media types")
 	siteMediaTypesConfig, err = media.DecodeTypes(mediaTypesConfig...)
@@ -151,11 +177,33 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		return nil, err
 	}
 
+	frontMatterHandler, err := pagemeta.NewFrontmatterHandler(cfg.Logger, cfg.Cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	taxonomies := cfg.Language.GetStringMapString("taxonomies")
+
+	var relatedContentConfig related.Config
+
+	if cfg.Language.IsSet("related") {
+		relatedContentConfig, err = related.DecodeConfig(cfg.Language.GetParams("related"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode related config: %w", err)
+		}
+	} else {
+		relatedContentConfig = related.DefaultConfig
+		if _, found := taxonomies["tag"]; found {
+			relatedContentConfig.Add(related.IndexConfig{Name: "tags", Weight: 80})
+		}
+	}
+
 	// KindTaxonomy, KindTerm like section title
 	titleFunc := helpers.GetTitleFunc("")
 
 	siteConfig := siteConfigHolder{
-		timeout: 30 * time.Second, // page content output init timeout
+		timeout:                30 * time.Second, // page content output init timeout
+		enableInlineShortcodes: cfg.Language.GetBool("enableInlineShortcodes"),
 	}
 
 	var siteBucket *pagesMapBucket
@@ -171,23 +219,164 @@ func newSite(cfg deps.DepsCfg) (*Site, error) {
 		siteCfg:   siteConfig,
 		titleFunc: titleFunc,
 
+		frontmatterHandler: frontMatterHandler,
+		relatedDocsHandler: page.NewRelatedDocsHandler(relatedContentConfig),
+
 		rc: &siteRenderingContext{output.HTMLFormat},
 	}
 
+	s.prepareInits()
+
 	return s, nil
 }
 
 type siteConfigHolder struct {
-	timeout time.Duration
+	timeout                time.Duration
+	enableInlineShortcodes bool
+}
+
+// Lazily loaded site dependencies.
+type siteInit struct {
+	prevNext          *lazy.Init
+	prevNextInSection *lazy.Init
+	menus             *lazy.Init
+}
+
+func (s *Site) prepareInits() {
+	s.init = &siteInit{}
+
+	var init lazy.Init
+
+	s.init.prevNext = init.Branch(func() (any, error) {
+		regularPages := s.RegularPages()
+		for i, p := range regularPages {
+			np, ok := p.(nextPrevProvider)
+			if !ok {
+				continue
+			}
+
+			pos := np.getNextPrev()
+			if pos == nil {
+				continue
+			}
+
+			pos.nextPage = nil
+			pos.prevPage = nil
+
+			if i > 0 {
+				pos.nextPage = regularPages[i-1]
+			}
+
+			if i < len(regularPages)-1 {
+				pos.prevPage = regularPages[i+1]
+			}
+		}
+		return nil, nil
+	})
+
+	s.init.prevNextInSection = init.Branch(func() (any, error) {
+		var sections page.Pages
+		s.home.treeRef.m.collectSectionsRecursiveIncludingSelf(pageMapQuery{Prefix: s.home.treeRef.key}, func(n *contentNode) {
+			sections = append(sections, n.p)
+		})
+
+		setNextPrev := func(pas page.Pages) {
+			for i, p := range pas {
+				np, ok := p.(nextPrevInSectionProvider)
+				if !ok {
+					continue
+				}
+
+				pos := np.getNextPrevInSection()
+				if pos == nil {
+					continue
+				}
+
+				pos.nextPage = nil
+				pos.prevPage = nil
+
+				if i > 0 {
+					pos.nextPage = pas[i-1]
+				}
+
+				if i < len(pas)-1 {
+					pos.prevPage = pas[i+1]
+				}
+			}
+		}
+
+		for _, sect := range sections {
+			treeRef := sect.(treeRefProvider).getTreeRef()
+
+			var pas page.Pages
+			treeRef.m.collectPages(pageMapQuery{Prefix: treeRef.key + cmBranchSeparator}, func(c *contentNode) {
+				pas = append(pas, c.p)
+			})
+			page.SortByDefault(pas)
+
+			setNextPrev(pas)
+		}
+
+		// The root section only goes one level down.
+		treeRef := s.home.getTreeRef()
+
+		var pas page.Pages
+		treeRef.m.collectPages(pageMapQuery{Prefix: treeRef.key + cmBranchSeparator}, func(c *contentNode) {
+			pas = append(pas, c.p)
+		})
+		page.SortByDefault(pas)
+
+		setNextPrev(pas)
+
+		return nil, nil
+	})
+
+	s.init.menus = init.Branch(func() (any, error) {
+		s.assembleMenus()
+		return nil, nil
+	})
+}
+
+func (s *Site) Menus() navigation.Menus {
+	s.init.menus.Do()
+	return s.menus
 }
 
 func (s *Site) initializeSiteInfo() error {
+	uglyURLs := func(p page.Page) bool {
+		return false
+	}
+
+	v := s.Cfg.Get("uglyURLs")
+	if v != nil {
+		switch vv := v.(type) {
+		case bool:
+			uglyURLs = func(p page.Page) bool {
+				return vv
+			}
+		case string:
+			vvv := cast.ToBool(vv)
+			uglyURLs = func(p page.Page) bool {
+				return vvv
+			}
+		default:
+			// Set per section, e.g. uglyURLs = { blog = true }
+			m := maps.ToStringMapBool(v)
+			uglyURLs = func(p page.Page) bool {
+				return m[p.Section()]
+			}
+		}
+	}
+
 	// Assemble dependencies to be used in hugo.Deps.
 	s.Info = &SiteInfo{
-		title:        "title",
-		relativeURLs: s.Cfg.GetBool("relativeURLs"),
-		owner:        s.h,
-		s:            s,
+		title:            s.language.GetString("title"),
+		relativeURLs:     s.Cfg.GetBool("relativeURLs"),
+		canonifyURLs:     s.Cfg.GetBool("canonifyURLs"),
+		uglyURLs:         uglyURLs,
+		sectionPagesMenu: s.Cfg.GetString("sectionPagesMenu"),
+		owner:            s.h,
+		s:                s,
 	}
 
 	return nil
@@ -209,6 +398,10 @@ func (s *SiteInfo) AllRegularPages() page.Pages {
 	return s.s.AllRegularPages()
 }
 
+func (s *SiteInfo) Menus() navigation.Menus {
+	return s.s.Menus()
+}
+
 func (s *SiteInfo) Title() string {
 	return s.title
 }
@@ -218,7 +411,7 @@ func (s *SiteInfo) Site() page.Site {
 }
 
 func (s *SiteInfo) Data() map[string]any {
-	return s.s.h.Data()
+	return s.s.h.Data(s.s.Lang())
 }
 
 // Current returns the currently rendered Site.
@@ -239,6 +432,42 @@ func (s *SiteInfo) BaseURL() template.URL {
 	return template.URL(s.s.PathSpec.BaseURL.String())
 }
 
+// GetPage looks up a page of a given type for the given ref.
+// In Hugo <= 0.44 you had to add Page Kind (section, home) etc. as the first
+// argument and then either a unix styled path (with or without a leading slash))
+// or path elements separated.
+// When we now remove the Kind from this API, we need to make the transition as painless
+// as possible for existing sites. Most sites will use {{ .Site.GetPage "section" "my/section" }},
+// i.e. 2 arguments, so we test for that.
+func (s *SiteInfo) GetPage(ref ...string) (page.Page, error) {
+	p, err := s.s.getPageOldVersion(ref...)
+
+	if p == nil {
+		// The nil struct has meaning in some situations, mostly to avoid breaking
+		// existing sites doing $nilpage.IsDescendant($p), which will always return
+		// false.
+		p = page.NilPage
+	}
+
+	return p, err
+}
+
+func (s *SiteInfo) GetPageWithTemplateInfo(info tpl.Info, ref ...string) (page.Page, error) {
+	p, err := s.GetPage(ref...)
+	if p != nil {
+		// Track pages referenced by templates/shortcodes
+		// when in server mode.
+		if im, ok := info.(identity.Manager); ok {
+			im.Add(p)
+		}
+	}
+	return p, err
+}
+
+func (s *Site) GetRelatedDocsHandler() *page.RelatedDocsHandler {
+	return s.relatedDocsHandler
+}
+
 func (s *Site) isEnabled(kind string) bool {
 	if kind == kindUnknown {
 		panic("Unknown kind")
@@ -272,7 +501,7 @@ func (s *Site) readAndProcessContent(buildConfig BuildCfg, filenames ...string)
 
 	proc := newPagesProcessor(s.h, sourceSpec)
 
-	c := newPagesCollector(sourceSpec, s.h.getContentMaps(), proc, filenames...)
+	c := newPagesCollector(sourceSpec, s.h.getContentMaps(), s.Log, proc, filenames...)
 
 	log.Process("readAndProcessContent", "collect content with PagesProcessor")
 	if err := c.Collect(); err != nil {
@@ -317,6 +546,10 @@ func (s *SiteInfo) Params() maps.Params {
 	return s.s.Language().Params()
 }
 
+func (s *SiteInfo) Language() *langs.Language {
+	return s.s.Language()
+}
+
 func (s *Site) Language() *langs.Language {
 	return s.language
 }
@@ -528,24 +761,102 @@ func (s *Site) lookupLayouts(layouts ...string) tpl.Template {
 }
 
 func newSiteRefLinker(cfg config.Provider, s *Site) (siteRefLinker, error) {
+	logger := s.Log.Error()
 
 	notFoundURL := cfg.GetString("refLinksNotFoundURL")
-
-	return siteRefLinker{s: s, notFoundURL: notFoundURL}, nil
+	errLevel := cfg.GetString("refLinksErrorLevel")
+	if strings.EqualFold(errLevel, "warning") {
+		logger = s.Log.Warn()
+	}
+	return siteRefLinker{s: s, errorLogger: logger, notFoundURL: notFoundURL}, nil
 }
 
 func (s siteRefLinker) logNotFound(ref, what string, p page.Page, position text.Position) {
 	if position.IsValid() {
-		fmt.Printf("[%s] REF_NOT_FOUND: Ref %q: %s: %s", s.s.Lang(), ref, position.String(), what)
+		s.errorLogger.Printf("[%s] REF_NOT_FOUND: Ref %q: %s: %s", s.s.Lang(), ref, position.String(), what)
 	} else if p == nil {
-		fmt.Printf("[%s] REF_NOT_FOUND: Ref %q: %s", s.s.Lang(), ref, what)
+		s.errorLogger.Printf("[%s] REF_NOT_FOUND: Ref %q: %s", s.s.Lang(), ref, what)
 	} else {
-		fmt.Printf("[%s] REF_NOT_FOUND: Ref %q from page %q: %s", s.s.Lang(), ref, p.Pathc(), what)
+		s.errorLogger.Printf("[%s] REF_NOT_FOUND: Ref %q from page %q: %s", s.s.Lang(), ref, p.Pathc(), what)
 	}
 }
 
 func (s *siteRefLinker) refLink(ref string, source any, relative bool, outputFormat string) (string, error) {
-	return "", nil
+	p, err := unwrapPage(source)
+	if err != nil {
+		return "", err
+	}
+
+	var refURL *url.URL
+
+	ref = filepath.ToSlash(ref)
+
+	refURL, err = url.Parse(ref)
+
+	if err != nil {
+		return s.notFoundURL, err
+	}
+
+	var target page.Page
+	var link string
+
+	if refURL.Path != "" {
+		var err error
+		target, err = s.s.getPageRef(p, refURL.Path)
+		var pos text.Position
+		if err != nil || target == nil {
+			if p, ok := source.(text.Positioner); ok {
+				pos = p.Position()
+			}
+		}
+
+		if err != nil {
+			s.logNotFound(refURL.Path, err.Error(), p, pos)
+			return s.notFoundURL, nil
+		}
+
+		if target == nil {
+			s.logNotFound(refURL.Path, "page not found", p, pos)
+			return s.notFoundURL, nil
+		}
+
+		var permalinker Permalinker = target
+
+		if outputFormat != "" {
+			o := target.OutputFormats().Get(outputFormat)
+
+			if o == nil {
+				s.logNotFound(refURL.Path, fmt.Sprintf("output format %q", outputFormat), p, pos)
+				return s.notFoundURL, nil
+			}
+			permalinker = o
+		}
+
+		if relative {
+			link = permalinker.RelPermalink()
+		} else {
+			link = permalinker.Permalink()
+		}
+	}
+
+	if refURL.Fragment != "" {
+		link = link + "#" + refURL.Fragment
+
+		if pctx, ok := target.(pageContext); ok {
+			if refURL.Path != "" {
+				if di, ok := pctx.getContentConverter().(converter.DocumentInfo); ok {
+					link = link + di.AnchorSuffix()
+				}
+			}
+		} else if pctx, ok := p.(pageContext); ok {
+			if di, ok := pctx.getContentConverter().(converter.DocumentInfo); ok {
+				link = link + di.AnchorSuffix()
+			}
+		}
+
+	}
+
+	return link, nil
 }
 
 func (s *Site) errorCollator(results <-chan error, errs chan<- error) {
@@ -558,3 +869,150 @@ func (s *Site) errorCollator(results <-chan error, errs chan<- error) {
 
 	close(errs)
 }
+
+func (s *Site) getMenusFromConfig() navigation.Menus {
+	ret := navigation.Menus{}
+
+	if menus := s.language.GetStringMap("menus"); menus != nil {
+		for name, menu := range menus {
+			m, err := cast.ToSliceE(menu)
+			if err != nil {
+				s.Log.Errorf("menus in site config contain errors\n")
+				s.Log.Errorln(err)
+			} else {
+				handleErr := func(err error) {
+					s.Log.Errorf("menus in site config contain errors\n")
+					s.Log.Errorln(err)
+				}
+
+				for _, entry := range m {
+					menuEntry := navigation.MenuEntry{Menu: name}
+					ime, err := maps.ToStringMapE(entry)
+					if err != nil {
+						handleErr(err)
+						continue
+					}
+
+					if err := menuEntry.MarshallMap(ime); err != nil {
+						handleErr(err)
+						continue
+					}
+
+					menuEntry.ConfiguredURL = s.Info.createNodeMenuEntryURL(menuEntry.ConfiguredURL)
+
+					if ret[name] == nil {
+						ret[name] = navigation.Menu{}
+					}
+					ret[name] = ret[name].Add(&menuEntry)
+				}
+			}
+		}
+		return ret
+	}
+	return ret
+}
+
+func (s *SiteInfo) createNodeMenuEntryURL(in string) string {
+	if !strings.HasPrefix(in, "/") {
+		return in
+	}
+	// make it match the nodes
+	menuEntryURL := s.s.PathSpec.URLize(in)
+	if !s.canonifyURLs {
+		menuEntryURL = s.s.PathSpec.PrependBasePath(menuEntryURL, false)
+	}
+	return menuEntryURL
+}
+
+func (s *Site) assembleMenus() {
+	s.menus = make(navigation.Menus)
+
+	type twoD struct {
+		MenuName, EntryName string
+	}
+	flat := map[twoD]*navigation.MenuEntry{}
+	children := map[twoD]navigation.Menu{}
+
+	// add menu entries from config to flat hash
+	menuConfig := s.getMenusFromConfig()
+	for name, menu := range menuConfig {
+		for _, me := range menu {
+			if types.IsNil(me.Page) && me.PageRef != "" {
+				// Try to resolve the page.
+				me.Page, _ = s.getPageNew(nil, me.PageRef)
+			}
+			flat[twoD{name, me.KeyName()}] = me
+		}
+	}
+
+	sectionPagesMenu := s.Info.sectionPagesMenu
+
+	if sectionPagesMenu != "" {
+		s.pageMap.sections.Walk(func(s string, v any) bool {
+			p := v.(*contentNode).p
+			if p.IsHome() {
+				return false
+			}
+			// Only the top level sections get an entry in the
+			// section pages menu.
+			id := p.Section()
+			if _, ok := flat[twoD{sectionPagesMenu, id}]; ok {
+				return false
+			}
+
+			me := navigation.MenuEntry{
+				Identifier: id,
+				Name:       p.LinkTitle(),
+				Weight:     p.Weight(),
+				Page:       p,
+			}
+			flat[twoD{sectionPagesMenu, me.KeyName()}] = &me
+
+			return false
+		})
+	}
+
+	// Add menu entries provided by pages
+	s.pageMap.pageTrees.WalkRenderable(func(ss string, n *contentNode) bool {
+		p := n.p
+
+		for name, me := range p.pageMenus.menus() {
+			if _, ok := flat[twoD{name, me.KeyName()}]; ok {
+				err := p.wrapError(fmt.Errorf("duplicate menu entry with identifier %q in menu %q", me.KeyName(), name))
+				s.Log.Warnln(err)
+				continue
+			}
+			flat[twoD{name, me.KeyName()}] = me
+		}
+
+		return false
+	})
+
+	// Create Children Menus First
+	for _, e := range flat {
+		if e.Parent != "" {
+			children[twoD{e.Menu, e.Parent}] = children[twoD{e.Menu, e.Parent}].Add(e)
+		}
+	}
+
+	// Placing Children in Parents (in flat)
+	for p, childmenu := range children {
+		_, ok := flat[twoD{p.MenuName, p.EntryName}]
+		if !ok {
+			// if parent does not exist, create one without a URL
+			flat[twoD{p.MenuName, p.EntryName}] = &navigation.MenuEntry{Name: p.EntryName}
+		}
+		flat[twoD{p.MenuName, p.EntryName}].Children = childmenu
+	}
+
+	// Assembling Top Level of Tree
+	for menu, e := range flat {
+		if e.Parent == "" {
+			_, ok := s.menus[menu.MenuName]
+			if !ok {
+				s.menus[menu.MenuName] = navigation.Menu{}
+			}
+			s.menus[menu.MenuName] = s.menus[menu.MenuName].Add(e)
+		}
+	}
+}
@@ -151,3 +151,19 @@ func (s siteRenderContext) renderSingletonPages() bool {
 	// 1 for all sites
 	return s.sitesOutIdx == 0
 }
+
+// renderMainLanguageRedirect creates a redirect to the main language home,
+// depending on if it lives in sub folder (e.g. /en) or not.
+func (s *Site) renderMainLanguageRedirect() error {
+	if len(s.h.Sites) < 2 {
+		// No need for a redirect
+		return nil
+	}
+
+	mainLang := s.PathSpec.DefaultContentLanguage
+	if s.Cfg.GetBool("defaultContentLanguageInSubdir") {
+		return s.publishDestAlias("/", s.PathSpec.AbsURL(mainLang+"/", false))
+	}
+
+	return s.publishDestAlias(mainLang, s.PathSpec.AbsURL("", false))
+}
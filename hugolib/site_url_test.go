@@ -0,0 +1,64 @@
+package hugolib
+
+import (
+	"testing"
+)
+
+func TestUglyURLsPerSection(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+disablePathToLower = true
+removePathAccents = true
+[uglyURLs]
+blog = true
+-- content/blog/Post.md --
+---
+title: "Post Title"
+---
+-- content/docs/Çafé Page.md --
+---
+title: "Doc"
+---
+-- layouts/index.html --
+HOME
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .RelPermalink }}|{{ .Permalink }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}|{{ .RelPermalink }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/Post.html", "SINGLE Post Title|/blog/Post.html|https://example.org/blog/Post.html")
+	b.AssertFileContent("blog.html", "LIST Blog|/blog.html")
+	b.AssertFileContent("docs/Cafe-Page/index.html", "SINGLE Doc|/docs/Cafe-Page/")
+	b.AssertFileContent("docs/index.html", "LIST Docs|/docs/")
+	b.AssertFileExists("blog/Post/index.html", false)
+}
+
+func TestUglyURLsGlobal(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+uglyURLs = true
+-- content/blog/Post.md --
+---
+title: "Post Title"
+---
+-- content/docs/Çafé Page.md --
+---
+title: "Doc"
+---
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .RelPermalink }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}|{{ .RelPermalink }}
+`
+
+	b := Test(t, files)
+
+	b.AssertFileContent("blog/post.html", "SINGLE Post Title|/blog/post.html")
+	b.AssertFileContent("docs/çafé-page.html", "SINGLE Doc|/docs/%C3%A7af%C3%A9-page.html")
+	b.AssertFileContent("docs.html", "LIST Docs|/docs.html")
+}
@@ -0,0 +1,44 @@
+package hugolib
+
+import (
+	"github.com/sunwei/hugo-playground/resources/page"
+)
+
+func pagesToTranslationsMap(sites []*Site) map[string]page.Pages {
+	out := make(map[string]page.Pages)
+
+	for _, s := range sites {
+		s.pageMap.pageTrees.Walk(func(ss string, n *contentNode) bool {
+			p := n.p
+			// TranslationKey is implemented for all page types.
+			base := p.TranslationKey()
+
+			pageTranslations, found := out[base]
+			if !found {
+				pageTranslations = make(page.Pages, 0)
+			}
+
+			pageTranslations = append(pageTranslations, p)
+			out[base] = pageTranslations
+
+			return false
+		})
+	}
+
+	return out
+}
+
+func assignTranslationsToPages(allTranslations map[string]page.Pages, sites []*Site) {
+	for _, s := range sites {
+		s.pageMap.pageTrees.Walk(func(ss string, n *contentNode) bool {
+			p := n.p
+			base := p.TranslationKey()
+			translations, found := allTranslations[base]
+			if !found {
+				return false
+			}
+			p.setTranslations(translations)
+			return false
+		})
+	}
+}
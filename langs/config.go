@@ -15,9 +15,12 @@ package langs
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/spf13/cast"
+	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/config"
 )
 
@@ -47,7 +50,10 @@ func LoadLanguageSettings(cfg config.Provider, oldLangs Languages) (c LanguagesC
 	if len(languages) == 0 {
 		languages2 = append(languages2, NewDefaultLanguage(cfg))
 	} else {
-		panic("no languages config params supported")
+		languages2, err = toSortedLanguages(cfg, languages)
+		if err != nil {
+			return c, fmt.Errorf("Failed to parse multilingual config: %w", err)
+		}
 	}
 
 	// oldLangs is nil
@@ -85,9 +91,9 @@ func LoadLanguageSettings(cfg config.Provider, oldLangs Languages) (c LanguagesC
 		return i < j
 	})
 
-	cfg.Set("languagesSorted", c.Languages)                    // ["en"]
-	cfg.Set("languagesSortedDefaultFirst", sortedDefaultFirst) // ["en"]
-	cfg.Set("multilingual", len(languages2) > 1)               // false
+	cfg.Set("languagesSorted", c.Languages)
+	cfg.Set("languagesSortedDefaultFirst", sortedDefaultFirst)
+	cfg.Set("multilingual", len(languages2) > 1)
 
 	for _, language := range c.Languages {
 		if language.initErr != nil {
@@ -97,3 +103,56 @@ func LoadLanguageSettings(cfg config.Provider, oldLangs Languages) (c LanguagesC
 
 	return c, nil
 }
+
+func toSortedLanguages(cfg config.Provider, l map[string]any) (Languages, error) {
+	languages := make(Languages, len(l))
+	i := 0
+
+	for lang, langConf := range l {
+		langsMap, err := maps.ToStringMapE(langConf)
+		if err != nil {
+			return nil, fmt.Errorf("Language config is not a map: %T", langConf)
+		}
+
+		language := NewLanguage(lang, cfg)
+
+		for loki, v := range langsMap {
+			switch loki {
+			case "title":
+				language.Title = cast.ToString(v)
+			case "languagename":
+				language.LanguageName = cast.ToString(v)
+			case "languagedirection":
+				language.LanguageDirection = cast.ToString(v)
+			case "weight":
+				language.Weight = cast.ToInt(v)
+			case "contentdir":
+				language.ContentDir = filepath.Clean(cast.ToString(v))
+			case "params":
+				m := maps.ToStringMap(v)
+				// Needed for case insensitive fetching of params values
+				maps.PrepareParams(m)
+				for k, vv := range m {
+					language.SetParam(k, vv)
+				}
+			case "timezone":
+				if err := language.loadLocation(cast.ToString(v)); err != nil {
+					return nil, err
+				}
+			}
+
+			// Put all into the Params map
+			language.SetParam(loki, v)
+
+			// Also set it in the configuration map (for baseURL etc.)
+			language.Set(loki, v)
+		}
+
+		languages[i] = language
+		i++
+	}
+
+	sort.Sort(languages)
+
+	return languages, nil
+}
@@ -19,9 +19,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gohugoio/locales"
+	translators "github.com/gohugoio/localescompressed"
+	"github.com/sunwei/hugo-playground/common/htime"
 	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/config"
 	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 // These are the settings that should only be looked up in the global Viper
@@ -41,8 +45,11 @@ var globalOnlySettings = map[string]bool{
 
 // Language manages specific-language configuration.
 type Language struct {
-	Lang   string
-	Weight int // for sort
+	Lang              string
+	LanguageName      string
+	LanguageDirection string
+	Title             string
+	Weight            int // for sort
 
 	// If set per language, this tells Hugo that all content files without any
 	// language indicator (e.g. my-page.en.md) is in this language.
@@ -63,8 +70,21 @@ type Language struct {
 	// For internal use.
 	config.Provider
 
+	// These are params declared in the [params] section of the language merged with the
+	// site's params, the most specific (language) wins on duplicate keys.
+	params    maps.Params
+	paramsMu  sync.Mutex
+	paramsSet bool
+
 	location *time.Location
 
+	// CLDR data used to format dates, e.g. month and day names.
+	translator    locales.Translator
+	timeFormatter htime.TimeFormatter
+
+	// Used for language-aware sorting of strings, e.g. page titles.
+	collator *Collator
+
 	// Error during initialization. Will fail the buld.
 	initErr error
 }
@@ -76,15 +96,46 @@ func (l *Language) String() string {
 
 // NewLanguage creates a new language.
 func NewLanguage(lang string, cfg config.Provider) *Language {
+	// Note that language specific params will be overridden later.
+	// We should improve that, but we need to make a copy:
+	params := make(map[string]any)
+	for k, v := range cfg.GetStringMap("params") {
+		params[k] = v
+	}
+	maps.PrepareParams(params)
+
 	localCfg := config.New()
 	compositeConfig := config.NewCompositeConfig(cfg, localCfg)
+	translator := translators.GetTranslator(lang)
+	if translator == nil {
+		translator = translators.GetTranslator(cfg.GetString("defaultContentLanguage"))
+		if translator == nil {
+			translator = translators.GetTranslator("en")
+		}
+	}
+
+	var coll *Collator
+	tag, err := language.Parse(lang)
+	if err == nil {
+		coll = &Collator{
+			c: collate.New(tag),
+		}
+	} else {
+		coll = &Collator{
+			c: collate.New(language.English),
+		}
+	}
 
 	l := &Language{
-		Lang:       lang,
-		ContentDir: cfg.GetString("contentDir"),
-		Cfg:        cfg,
-		LocalCfg:   localCfg,
-		Provider:   compositeConfig,
+		Lang:          lang,
+		ContentDir:    cfg.GetString("contentDir"),
+		Cfg:           cfg,
+		LocalCfg:      localCfg,
+		Provider:      compositeConfig,
+		params:        params,
+		translator:    translator,
+		timeFormatter: htime.NewTimeFormatter(translator),
+		collator:      coll,
 	}
 
 	if err := l.loadLocation(cfg.GetString("timeZone")); err != nil {
@@ -124,8 +175,13 @@ func (l Languages) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
 
 // Params returns language-specific params merged with the global params.
 func (l *Language) Params() maps.Params {
-	params := make(map[string]any)
-	return params
+	l.paramsMu.Lock()
+	defer l.paramsMu.Unlock()
+	if !l.paramsSet {
+		maps.PrepareParams(l.params)
+		l.paramsSet = true
+	}
+	return l.params
 }
 
 func (l Languages) AsSet() map[string]bool {
@@ -165,7 +221,12 @@ func (l Languages) IsMultihost() bool {
 // SetParam is case-insensitive.
 // For internal use.
 func (l *Language) SetParam(k string, v any) {
-	panic("params cannot be set")
+	l.paramsMu.Lock()
+	defer l.paramsMu.Unlock()
+	if l.paramsSet {
+		panic("params cannot be changed once set")
+	}
+	l.params[k] = v
 }
 
 // GetLocal gets a configuration value set on language level. It will
@@ -222,6 +283,21 @@ func (l *Language) loadLocation(tzStr string) error {
 	return nil
 }
 
+// GetTimeFormatter returns the locale aware TimeFormatter for the given language.
+func GetTimeFormatter(l *Language) htime.TimeFormatter {
+	return l.timeFormatter
+}
+
+// GetTranslator returns the CLDR translator for the given language.
+func GetTranslator(l *Language) locales.Translator {
+	return l.translator
+}
+
+// GetCollator returns the Collator to use for the given language.
+func GetCollator(l *Language) *Collator {
+	return l.collator
+}
+
 type Collator struct {
 	sync.Mutex
 	c *collate.Collator
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/spf13/afero"
+	"github.com/sunwei/hugo-playground/common/loggers"
 	"github.com/sunwei/hugo-playground/deps"
 	"github.com/sunwei/hugo-playground/hugofs"
 	"github.com/sunwei/hugo-playground/hugolib"
@@ -42,7 +43,7 @@ func main() {
 	fs := hugofs.NewFrom(afs, cfg, tempDir)
 
 	// 3. dependencies management
-	depsCfg := deps.DepsCfg{Cfg: cfg, Fs: fs}
+	depsCfg := deps.DepsCfg{Cfg: cfg, Fs: fs, Logger: loggers.NewWarningLogger()}
 
 	// 4. hugo sites
 	log.Process("main", "create hugo sites based on deps")
@@ -6,8 +6,44 @@ import (
 	"github.com/sunwei/hugo-playground/markup/highlight"
 	"github.com/sunwei/hugo-playground/markup/markup_config"
 	"strings"
+	"sync"
 )
 
+var (
+	registeredMu         sync.Mutex
+	registeredConverters []registeredConverter
+)
+
+type registeredConverter struct {
+	p       converter.ProviderProvider
+	aliases []string
+}
+
+// RegisterConverter registers a converter in addition to the built-in ones.
+// The converter can be looked up by its name and the given aliases, which are
+// usually file extensions, e.g. "mdx". Files in /content with one of these
+// extensions are picked up as content. Register from an init func or at least
+// before any site gets built; sites created before the call will not see it.
+func RegisterConverter(p converter.ProviderProvider, aliases ...string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registeredConverters = append(registeredConverters, registeredConverter{p: p, aliases: append([]string(nil), aliases...)})
+}
+
+// RegisteredAliases returns the lower case aliases of the registered
+// converters.
+func RegisteredAliases() []string {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	var aliases []string
+	for _, r := range registeredConverters {
+		for _, alias := range r.aliases {
+			aliases = append(aliases, strings.ToLower(alias))
+		}
+	}
+	return aliases
+}
+
 type ConverterProvider interface {
 	Get(name string) converter.Provider
 	GetMarkupConfig() markup_config.Config
@@ -37,7 +73,8 @@ func NewConverterProvider(cfg converter.ProviderConfig) (ConverterProvider, erro
 
 		name := c.Name()
 
-		aliases = append(aliases, name)
+		// Do not append to the caller's aliases.
+		aliases = append(append([]string(nil), aliases...), name)
 
 		if strings.EqualFold(name, defaultHandler) {
 			aliases = append(aliases, "markdown")
@@ -52,6 +89,16 @@ func NewConverterProvider(cfg converter.ProviderConfig) (ConverterProvider, erro
 		return nil, err
 	}
 
+	registeredMu.Lock()
+	registered := registeredConverters
+	registeredMu.Unlock()
+
+	for _, r := range registered {
+		if err := add(r.p, r.aliases...); err != nil {
+			return nil, err
+		}
+	}
+
 	return &converterRegistry{
 		config:     cfg,
 		converters: converters,
@@ -60,7 +107,7 @@ func NewConverterProvider(cfg converter.ProviderConfig) (ConverterProvider, erro
 
 func addConverter(m map[string]converter.Provider, c converter.Provider, aliases ...string) {
 	for _, alias := range aliases {
-		m[alias] = c
+		m[strings.ToLower(alias)] = c
 	}
 }
 
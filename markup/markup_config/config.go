@@ -1,6 +1,8 @@
 package markup_config
 
 import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/config"
 	"github.com/sunwei/hugo-playground/markup/asciidocext/asciidocext_config"
 	"github.com/sunwei/hugo-playground/markup/goldmark/goldmark_config"
@@ -23,7 +25,40 @@ type Config struct {
 }
 
 func Decode(cfg config.Provider) (conf Config, err error) {
-	return Default, nil
+	conf = Default
+
+	m := cfg.GetStringMap("markup")
+	if m == nil {
+		return
+	}
+	normalizeConfig(m)
+
+	err = mapstructure.WeakDecode(m, &conf)
+	if err != nil {
+		return
+	}
+
+	if err = highlight.ApplyLegacyConfig(cfg, &conf.Highlight); err != nil {
+		return
+	}
+
+	return
+}
+
+func normalizeConfig(m map[string]any) {
+	v, err := maps.GetNestedParam("goldmark.parser", ".", m)
+	if err != nil {
+		return
+	}
+	vm := maps.ToStringMap(v)
+	// Changed from a bool in 0.81.0
+	if vv, found := vm["attribute"]; found {
+		if vvb, ok := vv.(bool); ok {
+			vm["attribute"] = goldmark_config.ParserAttribute{
+				Title: vvb,
+			}
+		}
+	}
 }
 
 var Default = Config{
@@ -0,0 +1,84 @@
+package markup_test
+
+import (
+	"bytes"
+	"github.com/sunwei/hugo-playground/hugolib"
+	"github.com/sunwei/hugo-playground/identity"
+	"github.com/sunwei/hugo-playground/markup"
+	"github.com/sunwei/hugo-playground/markup/converter"
+	"strings"
+	"testing"
+)
+
+type upperProvider struct{}
+
+func (upperProvider) New(cfg converter.ProviderConfig) (converter.Provider, error) {
+	return converter.NewProvider("upper", func(ctx converter.DocumentContext) (converter.Converter, error) {
+		return upperConverter{}, nil
+	}), nil
+}
+
+type upperConverter struct{}
+
+func (upperConverter) Convert(ctx converter.RenderContext) (converter.Result, error) {
+	return bytes.NewBufferString("<p>" + strings.ToUpper(strings.TrimSpace(string(ctx.Src))) + "</p>"), nil
+}
+
+func (upperConverter) Supports(feature identity.Identity) bool { return false }
+
+// Room for more aliases in the backing array, which must be left alone.
+var upperAliases = append(make([]string, 0, 4), "mydoc", "TXT")
+
+func init() {
+	markup.RegisterConverter(upperProvider{}, upperAliases...)
+}
+
+func TestRegisterConverter(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/a.mydoc --
+---
+title: "A"
+---
+hello mydoc
+-- content/b.txt --
+---
+title: "B"
+---
+hello txt
+-- content/c.md --
+---
+title: "C"
+markup: "upper"
+---
+hello md as upper
+-- content/d.md --
+---
+title: "D"
+---
+*plain md*
+-- content/e.pdf --
+not content
+-- layouts/index.html --
+HOME{{ range .Site.RegularPages }}|{{ .Title }}{{ end }}
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .Content }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "HOME|A|B|C|D")
+	b.AssertFileContent("a/index.html", "SINGLE A|<p>HELLO MYDOC</p>")
+	b.AssertFileContent("b/index.html", "SINGLE B|<p>HELLO TXT</p>")
+	b.AssertFileContent("c/index.html", "SINGLE C|<p>HELLO MD AS UPPER</p>")
+	b.AssertFileContent("d/index.html", "SINGLE D|<p><em>plain md</em></p>")
+
+	aliases := markup.RegisteredAliases()
+	if strings.Join(aliases, ",") != "mydoc,txt" {
+		t.Errorf("got aliases %v", aliases)
+	}
+	if extra := upperAliases[:4]; extra[2] != "" || extra[3] != "" {
+		t.Errorf("the registered aliases' backing array was modified: %q", extra)
+	}
+}
@@ -2,6 +2,7 @@ package media
 
 import (
 	"fmt"
+	"net/http"
 	"sort"
 	"strings"
 )
@@ -118,16 +119,36 @@ func (m Type) Type() string {
 	return m.MainType + "/" + m.SubType
 }
 
+// For internal use.
+func (m Type) String() string {
+	return m.Type()
+}
+
 // Definitions from https://developer.mozilla.org/en-US/docs/Web/HTTP/Basics_of_HTTP/MIME_types etc.
 // Note that from Hugo 0.44 we only set Suffix if it is part of the MIME type.
 var (
-	HTMLType = newMediaType("text", "html", []string{"html"})
+	CSSType        = newMediaType("text", "css", []string{"css"})
+	HTMLType       = newMediaType("text", "html", []string{"html"})
+	JavascriptType = newMediaType("application", "javascript", []string{"js", "jsm", "mjs"})
+	TypeScriptType = newMediaType("application", "typescript", []string{"ts"})
+	TSXType        = newMediaType("text", "tsx", []string{"tsx"})
+	JSXType        = newMediaType("text", "jsx", []string{"jsx"})
 
 	JSONType = newMediaType("application", "json", []string{"json"})
 	TOMLType = newMediaType("application", "toml", []string{"toml"})
+	XMLType  = newMediaType("application", "xml", []string{"xml"})
+	CSVType  = newMediaType("text", "csv", []string{"csv"})
 
 	MarkdownType = newMediaType("text", "markdown", []string{"md", "markdown"})
 
+	// Common image types
+	PNGType  = newMediaType("image", "png", []string{"png"})
+	JPEGType = newMediaType("image", "jpeg", []string{"jpg", "jpeg", "jpe", "jif", "jfif"})
+	GIFType  = newMediaType("image", "gif", []string{"gif"})
+	TIFFType = newMediaType("image", "tiff", []string{"tif", "tiff"})
+	BMPType  = newMediaType("image", "bmp", []string{"bmp"})
+	WEBPType = newMediaType("image", "webp", []string{"webp"})
+
 	OctetType = newMediaType("application", "octet-stream", nil)
 
 	TextType = newMediaType("text", "plain", []string{"txt"})
@@ -135,9 +156,23 @@ var (
 
 // DefaultTypes is the default media types supported by Hugo.
 var DefaultTypes = Types{
+	CSSType,
 	HTMLType,
 	MarkdownType,
+	JavascriptType,
+	TypeScriptType,
+	TSXType,
+	JSXType,
+	JSONType,
+	XMLType,
 	TOMLType,
+	CSVType,
+	PNGType,
+	JPEGType,
+	GIFType,
+	TIFFType,
+	BMPType,
+	WEBPType,
 	TextType,
 }
 
@@ -206,6 +241,72 @@ func (m Type) hasSuffix(suffix string) bool {
 	return strings.Contains(","+m.suffixesCSV+",", ","+suffix+",")
 }
 
+// FromContent resolve the Type primarily using http.DetectContentType.
+// If http.DetectContentType resolves to application/octet-stream, a zero Type is returned.
+// If http.DetectContentType  resolves to text/plain or application/xml, we try to get more specific using types and ext.
+func FromContent(types Types, extensionHints []string, content []byte) Type {
+	var zero Type
+
+	t := strings.Split(http.DetectContentType(content), ";")[0]
+	if t == "application/octet-stream" {
+		return zero
+	}
+
+	var found bool
+	m, found := types.GetByType(t)
+	if !found {
+		if t == "text/xml" {
+			// This is how it's configured in Hugo by default.
+			m, found = types.GetByType("application/xml")
+		}
+	}
+
+	if !found {
+		return zero
+	}
+
+	var mm Type
+
+	for _, extension := range extensionHints {
+		extension = strings.TrimPrefix(extension, ".")
+		mm, _, found = types.GetFirstBySuffix(extension)
+		if found {
+			break
+		}
+	}
+
+	if found {
+		if m == mm {
+			return m
+		}
+
+		if m.IsText() && mm.IsText() {
+			// http.DetectContentType isn't brilliant when it comes to common text formats, so we need to do better.
+			// For now we say that if it's detected to be a text format and the extension/content type in header reports
+			// it to be a text format, then we use that.
+			return mm
+		}
+
+		// E.g. an image with a *.js extension.
+		return zero
+	}
+
+	return m
+}
+
+// IsText returns whether this Type is a text format.
+// Note that this may currently return false negatives.
+func (m Type) IsText() bool {
+	if m.MainType == "text" {
+		return true
+	}
+	switch m.SubType {
+	case "javascript", "json", "rss", "xml", "svg", TOMLType.SubType:
+		return true
+	}
+	return false
+}
+
 // IsZero reports whether this Type represents a zero value.
 // For internal use.
 func (m Type) IsZero() bool {
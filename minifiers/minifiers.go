@@ -78,6 +78,11 @@ func getMinifier(c minifyConfig, s string) minify.Minifier {
 	}
 }
 
+// Minify tries to minify the src into dst given a MIME type.
+func (m Client) Minify(mediatype media.Type, dst io.Writer, src io.Reader) error {
+	return m.m.Minify(mediatype.Type(), dst, src)
+}
+
 // noopMinifier implements minify.Minifier [1], but doesn't minify content. This means
 // that we can avoid missing minifiers for any MIME types in our minify.M, which
 // causes minify to return errors, while still allowing minification to be
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"fmt"
+	"github.com/spf13/cast"
 	"github.com/sunwei/hugo-playground/config"
 	"github.com/sunwei/hugo-playground/hugofs/files"
 	"github.com/sunwei/hugo-playground/langs"
@@ -99,7 +100,7 @@ func ApplyProjectConfigDefaults(cfg config.Provider, mod Module) error {
 
 	dirKeys := []dirKeyComponent{
 		{"contentDir", files.ComponentFolderContent, true},
-		{"dataDir", files.ComponentFolderData, false},
+		{"dataDir", files.ComponentFolderData, true},
 		{"layoutDir", files.ComponentFolderLayouts, false},
 		{"i18nDir", files.ComponentFolderI18n, false},
 		{"archetypeDir", files.ComponentFolderArchetypes, false},
@@ -173,6 +174,15 @@ func ApplyProjectConfigDefaults(cfg config.Provider, mod Module) error {
 
 				componentsConfigured[d.component] = len(seen) > 0
 
+			} else if d.component == files.ComponentFolderData {
+				// Data shared by all languages, plus any language specific data
+				// dirs on top.
+				mounts = append(mounts, createMountsFor(d, cfg)...)
+				for _, language := range languages {
+					if dataDir := cast.ToString(language.GetLocal(d.key)); dataDir != "" {
+						mounts = append(mounts, Mount{Lang: language.Lang, Source: dataDir, Target: d.component})
+					}
+				}
 			} else {
 				for _, language := range languages {
 					mounts = append(mounts, createMountsFor(d, language)...)
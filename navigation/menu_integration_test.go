@@ -0,0 +1,97 @@
+package navigation_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+const menuTemplate = `{{ $p := . }}
+{{ range .Site.Menus.main }}- {{ .Pre }}{{ .Name }}|{{ .URL }}|{{ .Weight }}|cur={{ $p.IsMenuCurrent "main" . }}|has={{ $p.HasMenuCurrent "main" . }}|{{ .Params.class }}
+{{ range .Children }}  - {{ .Name }}|{{ .URL }}|cur={{ $p.IsMenuCurrent "main" . }}
+{{ end }}{{ end }}footer:{{ range .Site.Menus.footer }}{{ .Name }}|{{ .URL }}{{ end }}`
+
+func TestMenus(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/docs/"
+sectionPagesMenu = "main"
+[[menus.main]]
+name = "Home"
+url = "/"
+weight = 1
+[[menus.main]]
+name = "About"
+pageRef = "/about"
+weight = 5
+pre = "<i>"
+[menus.main.params]
+class = "x"
+[[menus.footer]]
+name = "GitHub"
+url = "https://github.com"
+-- content/about.md --
+---
+title: "About Us"
+---
+-- content/blog/post.md --
+---
+title: "Post Title"
+menu:
+  main:
+    parent: blog
+    weight: 3
+---
+-- content/blog/other.md --
+---
+title: "Other"
+menu: main
+weight: 2
+---
+-- layouts/index.html --
+HOME` + menuTemplate + `
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}` + menuTemplate + `
+-- layouts/_default/list.html --
+LIST {{ .Title }}` + menuTemplate + `
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+- Home|/docs/|1|
+- Other|/docs/blog/other/|2|
+- <i>About|/docs/about/|5|cur=false|has=false|x
+- Blog|/docs/blog/|0|cur=false|has=false|
+  - Post Title|/docs/blog/post/|cur=false
+footer:GitHub|https://github.com
+`)
+	b.AssertFileContent("about/index.html", "- <i>About|/docs/about/|5|cur=true|has=false|x")
+	b.AssertFileContent("blog/index.html", "- Blog|/docs/blog/|0|cur=true|has=false|")
+	b.AssertFileContent("blog/post/index.html", `
+- Blog|/docs/blog/|0|cur=false|has=true|
+  - Post Title|/docs/blog/post/|cur=true
+`)
+}
+
+func TestMenusInvalidConfigEntry(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[[menus.main]]
+name = "Good"
+url = "/good/"
+[[menus.main]]
+name = "Bad"
+url = "/bad/"
+params = "not a map"
+-- layouts/index.html --
+{{ range .Site.Menus.main }}{{ .Name }}|{{ end }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	if err := b.BuildE(); err == nil {
+		t.Fatal("expected the menu error to fail the build")
+	}
+	b.AssertLogContains(`failed to marshal menu entry "Bad"`)
+	b.AssertFileContent("index.html", "Good|", "! Bad|")
+}
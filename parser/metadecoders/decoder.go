@@ -1,7 +1,12 @@
 package metadecoders
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	xml "github.com/clbanning/mxj/v2"
+	"github.com/mitchellh/mapstructure"
 	toml "github.com/pelletier/go-toml/v2"
 	"github.com/spf13/afero"
 	"github.com/spf13/cast"
@@ -24,6 +29,66 @@ var Default = Decoder{
 	Delimiter: ',',
 }
 
+// DecodeDecoder creates a Decoder from the given options map, e.g.
+// {"delimiter": ";", "comment": "#"}. Missing options keep their defaults.
+func DecodeDecoder(m map[string]any) (Decoder, error) {
+	opts := Default
+
+	if m == nil {
+		return opts, nil
+	}
+
+	rest := make(map[string]any)
+
+	// mapstructure does not support string to rune conversion, so do that manually.
+	// See https://github.com/mitchellh/mapstructure/issues/151
+	for k, v := range m {
+		if strings.EqualFold(k, "Delimiter") {
+			r, err := stringToRune(v)
+			if err != nil {
+				return opts, err
+			}
+			opts.Delimiter = r
+
+		} else if strings.EqualFold(k, "Comment") {
+			r, err := stringToRune(v)
+			if err != nil {
+				return opts, err
+			}
+			opts.Comment = r
+		} else {
+			rest[k] = v
+		}
+	}
+
+	err := mapstructure.WeakDecode(rest, &opts)
+
+	return opts, err
+}
+
+func stringToRune(v any) (rune, error) {
+	s, err := cast.ToStringE(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	var r rune
+
+	for i, rr := range s {
+		if i == 0 {
+			r = rr
+		} else {
+			return 0, fmt.Errorf("invalid character: %q", v)
+		}
+	}
+
+	return r, nil
+}
+
 // UnmarshalFileToMap is the same as UnmarshalToMap, but reads the data from
 // the given filename.
 func (d Decoder) UnmarshalFileToMap(fs afero.Fs, filename string) (map[string]any, error) {
@@ -57,6 +122,33 @@ func (d Decoder) UnmarshalTo(data []byte, f Format, v any) error {
 	var err error
 
 	switch f {
+	case JSON:
+		err = json.Unmarshal(data, v)
+	case XML:
+		var xmlRoot xml.Map
+		xmlRoot, err = xml.NewMapXml(data)
+
+		var xmlValue map[string]any
+		if err == nil {
+			xmlRootName, err := xmlRoot.Root()
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal XML: %w", err)
+			}
+			switch rv := xmlRoot[xmlRootName].(type) {
+			case map[string]any:
+				xmlValue = rv
+			default:
+				// A root without child elements, e.g. <version>1.2</version>.
+				xmlValue = map[string]any{xmlRootName: rv}
+			}
+		}
+
+		switch v := v.(type) {
+		case *map[string]any:
+			*v = xmlValue
+		case *any:
+			*v = xmlValue
+		}
 	case TOML:
 		err = toml.Unmarshal(data, v)
 	case YAML:
@@ -89,6 +181,9 @@ func (d Decoder) UnmarshalTo(data []byte, f Format, v any) error {
 				}
 			}
 		}
+	case CSV:
+		return d.unmarshalCSV(data, v)
+
 	default:
 		return fmt.Errorf("unmarshal of format %q is not supported", f)
 	}
@@ -105,6 +200,8 @@ func (d Decoder) UnmarshalTo(data []byte, f Format, v any) error {
 func (d Decoder) Unmarshal(data []byte, f Format) (any, error) {
 	if data == nil {
 		switch f {
+		case CSV:
+			return make([][]string, 0), nil
 		default:
 			return make(map[string]any), nil
 		}
@@ -115,6 +212,27 @@ func (d Decoder) Unmarshal(data []byte, f Format) (any, error) {
 	return v, err
 }
 
+func (d Decoder) unmarshalCSV(data []byte, v any) error {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = d.Delimiter
+	r.Comment = d.Comment
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	switch v.(type) {
+	case *any:
+		*v.(*any) = records
+	default:
+		return fmt.Errorf("CSV cannot be unmarshaled into %T", v)
+
+	}
+
+	return nil
+}
+
 // OptionsKey is used in cache keys.
 func (d Decoder) OptionsKey() string {
 	var sb strings.Builder
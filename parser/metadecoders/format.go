@@ -28,8 +28,16 @@ func FormatFromString(formatStr string) Format {
 		formatStr = strings.TrimPrefix(filepath.Ext(formatStr), ".")
 	}
 	switch formatStr {
+	case "json":
+		return JSON
 	case "toml":
 		return TOML
+	case "yaml", "yml":
+		return YAML
+	case "csv":
+		return CSV
+	case "xml":
+		return XML
 	}
 
 	return ""
@@ -0,0 +1,90 @@
+package related_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+const relatedContent = `
+-- content/blog/a.md --
+---
+title: "A"
+date: 2021-01-01
+tags: ["go", "hugo"]
+keywords: ["static"]
+---
+-- content/blog/b.md --
+---
+title: "B"
+date: 2021-05-01
+tags: ["go"]
+---
+-- content/blog/c.md --
+---
+title: "C"
+date: 2019-01-01
+tags: ["rust"]
+keywords: ["static"]
+---
+-- content/blog/d.md --
+---
+title: "D"
+date: 2015-01-01
+tags: ["cooking"]
+---
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|REL:{{ range .Site.RegularPages.Related . }}{{ .Title }},{{ end }}|IDX:{{ range .Site.RegularPages.RelatedIndices . "tags" }}{{ .Title }},{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+`
+
+func TestRelated(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[related]
+threshold = 50
+includeNewer = true
+[[related.indices]]
+name = "keywords"
+weight = 100
+[[related.indices]]
+name = "tags"
+weight = 80
+[[related.indices]]
+name = "date"
+weight = 10
+pattern = "2006"
+-- layouts/index.html --
+TO:{{ range .Site.RegularPages.RelatedTo (keyVals "tags" "go") }}{{ .Title }},{{ end }}
+` + relatedContent
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("blog/a/index.html", "SINGLE A|REL:C,B,|IDX:B,")
+	b.AssertFileContent("blog/b/index.html", "SINGLE B|REL:A,|IDX:A,")
+	b.AssertFileContent("blog/c/index.html", "SINGLE C|REL:A,|IDX:")
+	b.AssertFileContent("blog/d/index.html", "SINGLE D|REL:|IDX:")
+	b.AssertFileContent("index.html", "TO:B,A,")
+}
+
+func TestRelatedExcludeNewer(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[related]
+threshold = 50
+[[related.indices]]
+name = "keywords"
+weight = 100
+[[related.indices]]
+name = "tags"
+weight = 80
+` + relatedContent
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("blog/a/index.html", "SINGLE A|REL:C,|")
+	b.AssertFileContent("blog/b/index.html", "SINGLE B|REL:A,|")
+	b.AssertFileContent("blog/c/index.html", "SINGLE C|REL:|")
+}
@@ -0,0 +1,135 @@
+package resources_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestImages writes a gradient image in the given formats to dir.
+func writeTestImages(t *testing.T, dir string, w, h int, names ...string) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / w), G: uint8(y * 255 / h), B: 128, A: 255})
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch filepath.Ext(name) {
+		case ".png":
+			err = png.Encode(f, img)
+		case ".jpg":
+			err = jpeg.Encode(f, img, &jpeg.Options{Quality: 90})
+		case ".gif":
+			err = gif.Encode(f, img, nil)
+		}
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImageProcessing(t *testing.T) {
+	wd := t.TempDir()
+	writeTestImages(t, filepath.Join(wd, "assets", "images"), 400, 200, "sunset.jpg", "logo.png", "card.gif")
+	writeTestImages(t, filepath.Join(wd, "content", "post"), 60, 40, "pic.png")
+
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[imaging]
+quality = 80
+resampleFilter = "lanczos"
+-- content/post/index.md --
+---
+title: "Post"
+---
+-- layouts/index.html --
+{{ $s := resources.Get "images/sunset.jpg" }}SRC {{ $s.RelPermalink }}|{{ $s.MediaType }}|{{ $s.ResourceType }}|{{ $s.Width }}x{{ $s.Height }}
+{{ $r := $s.Resize "300x" }}RESIZE {{ $r.Width }}x{{ $r.Height }}|{{ $r.MediaType }}
+{{ $f := $s.Fit "100x100" }}FIT {{ $f.Width }}x{{ $f.Height }}
+{{ $fi := $s.Fill "100x100 smart" }}FILL {{ $fi.Width }}x{{ $fi.Height }}
+{{ $fa := $s.Fill "100x50 TopLeft q20" }}FILLA {{ $fa.Width }}x{{ $fa.Height }}
+{{ $c := $s.Crop "50x60 Center" }}CROP {{ $c.Width }}x{{ $c.Height }}
+{{ $png := $s.Resize "64x png r90" }}PNG {{ $png.MediaType }}|{{ $png.Width }}x{{ $png.Height }}
+{{ $g := $s.Filter images.Grayscale (images.GaussianBlur 3) (images.Brightness 10) (images.Contrast 5) }}FILTER {{ $g.Width }}
+{{ $o := $r.Filter (images.Overlay (resources.Get "images/logo.png") 10 10) }}OVERLAY {{ $o.Width }}
+COLORS {{ gt (len $s.Colors) 0 }}
+EXIF {{ with $s.Exif }}{{ .Date }}{{ else }}none{{ end }}
+{{ $gr := (resources.Get "images/card.gif").Resize "40x" }}GIF {{ $gr.MediaType }}|{{ $gr.Width }}x{{ $gr.Height }}
+{{ $cfg := imageConfig "assets/images/logo.png" }}CFG {{ $cfg.Width }}x{{ $cfg.Height }}
+{{ with .Site.GetPage "post" }}{{ range .Resources.ByType "image" }}BUNDLE {{ .Name }}|{{ with .Resize "20x" }}{{ .RelPermalink }}|{{ .Width }}x{{ .Height }}{{ end }}{{ end }}{{ end }}
+RESIZED {{ $r.RelPermalink }}
+-- layouts/_default/single.html --
+S
+-- layouts/_default/list.html --
+L
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files, WorkingDir: wd}).Build()
+
+	b.AssertFileContent("index.html", `
+SRC /images/sunset.jpg|image/jpeg|image|400x200
+RESIZE 300x150|image/jpeg
+FIT 100x50
+FILL 100x100
+FILLA 100x50
+CROP 50x60
+PNG image/png|64x128
+FILTER 400
+OVERLAY 300
+COLORS true
+EXIF none
+GIF image/gif|40x20
+CFG 400x200
+BUNDLE pic.png|/post/pic_
+|20x13
+`)
+
+	content := b.FileContent("index.html")
+	resized := content[strings.Index(content, "RESIZED ")+len("RESIZED "):]
+	resized = strings.TrimSpace(resized)
+	if !strings.Contains(resized, "_300x0_resize_q80_lanczos.jpg") {
+		t.Fatalf("unexpected resized image name %q", resized)
+	}
+	b.AssertFileExists(resized, true)
+
+	// Processed images are cached under resourceDir, keyed by source hash and options.
+	gen, err := filepath.Glob(filepath.Join(wd, "resources", "_gen", "images", "sunset_hu*_300x0_resize_q80_lanczos.jpg"))
+	if err != nil || len(gen) != 1 {
+		t.Fatalf("expected the resized image in the image cache, got %v (%v)", gen, err)
+	}
+
+	// A second build reuses the cached image.
+	fi, err := os.Stat(gen[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Build()
+	fi2, err := os.Stat(gen[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(fi2.ModTime()) {
+		t.Fatal("expected the cached image to be reused")
+	}
+}
@@ -11,6 +11,13 @@ type ResourceTransformationKey struct {
 	elements []any
 }
 
+// NewResourceTransformationKey creates a new ResourceTransformationKey from the transformation
+// name and elements. We will create a 64 bit FNV hash from the elements, which when combined
+// with the other key elements should be unique for all practical applications.
+func NewResourceTransformationKey(name string, elements ...any) ResourceTransformationKey {
+	return ResourceTransformationKey{Name: name, elements: elements}
+}
+
 // Value returns the Key as a string.
 // Do not change this without good reasons.
 func (k ResourceTransformationKey) Value() string {
@@ -0,0 +1,12 @@
+package page
+
+// SortCacheLen returns the number of cached page orderings.
+func SortCacheLen() int {
+	spc.RLock()
+	defer spc.RUnlock()
+	n := 0
+	for _, entries := range spc.m {
+		n += len(entries)
+	}
+	return n
+}
@@ -2,7 +2,9 @@ package page
 
 import (
 	"fmt"
+	"github.com/sunwei/hugo-playground/compare"
 	"github.com/sunwei/hugo-playground/identity"
+	"github.com/sunwei/hugo-playground/navigation"
 	"github.com/sunwei/hugo-playground/related"
 	"github.com/sunwei/hugo-playground/resources/resource"
 	"github.com/sunwei/hugo-playground/source"
@@ -47,6 +49,19 @@ type ContentProvider interface {
 	Len() int
 }
 
+// TranslationsProvider provides access to any translations.
+type TranslationsProvider interface {
+	// IsTranslated returns whether this content file is translated to
+	// other language(s).
+	IsTranslated() bool
+
+	// AllTranslations returns all translations, including the current Page.
+	AllTranslations() Pages
+
+	// Translations returns the translations excluding the current Page.
+	Translations() Pages
+}
+
 // TableOfContentsProvider provides the table of contents for a Page.
 type TableOfContentsProvider interface {
 	TableOfContents() template.HTML
@@ -61,6 +76,10 @@ type RawContentProvider interface {
 type PageWithoutContent interface {
 	resource.Resource
 	PageMetaProvider
+	resource.LanguageProvider
+	RefProvider
+	ShortcodeInfoProvider
+	GetPageProvider
 
 	// FileProvider For pages backed by a file.
 	FileProvider
@@ -70,11 +89,20 @@ type PageWithoutContent interface {
 
 	TreeProvider
 
+	// Horizontal navigation
+	InSectionPositioner
+	Positioner
+
 	SitesProvider
+	resource.TranslationKeyProvider
+	TranslationsProvider
+	navigation.PageMenusProvider
 	identity.Provider
 	PaginatorProvider
 	PageRenderProvider
 	AlternativeOutputFormatsProvider
+	RelatedKeywordsProvider
+	compare.Eqer
 }
 
 // PageMetaProvider provides page metadata, typically provided via front matter.
@@ -118,6 +146,9 @@ type PageMetaProvider interface {
 	// Slug The slug, typically defined in front matter.
 	Slug() string
 
+	// Lang This page's language code. Will be the same as the site's.
+	Lang() string
+
 	// IsSection returns whether this is a section
 	IsSection() bool
 
@@ -145,6 +176,12 @@ type FileProvider interface {
 	File() source.File
 }
 
+// InternalDependencies is considered an internal interface.
+type InternalDependencies interface {
+	// GetRelatedDocsHandler is for internal use only.
+	GetRelatedDocsHandler() *RelatedDocsHandler
+}
+
 // OutputFormatsProvider provides the OutputFormats of a Page.
 type OutputFormatsProvider interface {
 	OutputFormats() OutputFormats
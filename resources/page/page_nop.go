@@ -24,6 +24,7 @@ import (
 	"github.com/sunwei/hugo-playground/identity"
 	"github.com/sunwei/hugo-playground/langs"
 	"github.com/sunwei/hugo-playground/media"
+	"github.com/sunwei/hugo-playground/navigation"
 	"github.com/sunwei/hugo-playground/related"
 	"github.com/sunwei/hugo-playground/resources/resource"
 	"github.com/sunwei/hugo-playground/source"
@@ -200,6 +201,18 @@ func (p *nopPage) IsAncestor(other any) (bool, error) {
 	return false, nil
 }
 
+func (p *nopPage) HasMenuCurrent(menuID string, me *navigation.MenuEntry) bool {
+	return false
+}
+
+func (p *nopPage) IsMenuCurrent(menuID string, inme *navigation.MenuEntry) bool {
+	return false
+}
+
+func (p *nopPage) Menus() (m navigation.PageMenus) {
+	return
+}
+
 func (p *nopPage) IsDescendant(other any) (bool, error) {
 	return false, nil
 }
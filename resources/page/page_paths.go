@@ -58,6 +58,9 @@ type TargetPathDescriptor struct {
 	// if set in URL.
 	ForcePrefix bool
 
+	// Whether to publish e.g. section/page.html instead of section/page/index.html.
+	UglyURLs bool
+
 	// URL from front matter if set. Will override any Slug etc.
 	URL string
 
@@ -100,7 +103,7 @@ func CreateTargetPaths(d TargetPathDescriptor) (tp TargetPaths) {
 	// the index base even when uglyURLs is enabled.
 	needsBase := true
 
-	isUgly := false
+	isUgly := d.UglyURLs
 	baseNameSameAsType := d.BaseName != "" && d.BaseName == d.Type.BaseName
 
 	if d.ExpandedPermalink == "" && baseNameSameAsType {
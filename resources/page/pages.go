@@ -1,9 +1,90 @@
 package page
 
+import (
+	"fmt"
+	"github.com/sunwei/hugo-playground/compare"
+	"github.com/sunwei/hugo-playground/resources/resource"
+)
+
 // Pages is a slice of Page objects. This is the most common list type in Hugo.
 type Pages []Page
 
+// String returns a string representation of the list.
+// For internal use.
+func (ps Pages) String() string {
+	return fmt.Sprintf("Pages(%d)", len(ps))
+}
+
+// ToResources wraps resource.ResourcesConverter.
+// For internal use.
+func (pages Pages) ToResources() resource.Resources {
+	r := make(resource.Resources, len(pages))
+	for i, p := range pages {
+		r[i] = p
+	}
+	return r
+}
+
+// Group groups the pages in in by key.
+// This implements collections.Grouper.
+func (p Pages) Group(key any, in any) (any, error) {
+	pages, err := ToPages(in)
+	if err != nil {
+		return PageGroup{}, err
+	}
+	return PageGroup{Key: key, Pages: pages}, nil
+}
+
 // Len returns the number of pages in the list.
 func (p Pages) Len() int {
 	return len(p)
 }
+
+// ProbablyEq wraps compare.ProbablyEqer
+// For internal use.
+func (pages Pages) ProbablyEq(other any) bool {
+	otherPages, ok := other.(Pages)
+	if !ok {
+		return false
+	}
+
+	if len(pages) != len(otherPages) {
+		return false
+	}
+
+	step := 1
+
+	for i := 0; i < len(pages); i += step {
+		if !pages[i].Eq(otherPages[i]) {
+			return false
+		}
+
+		if i > 50 {
+			// This is most likely the same.
+			step = 50
+		}
+	}
+
+	return true
+}
+
+func (ps Pages) removeFirstIfFound(p Page) Pages {
+	ii := -1
+	for i, pp := range ps {
+		if p.Eq(pp) {
+			ii = i
+			break
+		}
+	}
+
+	if ii != -1 {
+		copy(ps[ii:], ps[ii+1:])
+		ps = ps[:len(ps)-1]
+	}
+	return ps
+}
+
+var (
+	_ resource.ResourcesConverter = Pages{}
+	_ compare.ProbablyEqer        = Pages{}
+)
@@ -0,0 +1,21 @@
+package page
+
+// Next returns the next page relative to the given
+func (p Pages) Next(cur Page) Page {
+	x := searchPage(cur, p)
+	if x <= 0 {
+		return nil
+	}
+	return p[x-1]
+}
+
+// Prev returns the previous page reletive to the given
+func (p Pages) Prev(cur Page) Page {
+	x := searchPage(cur, p)
+
+	if x == -1 || len(p)-x < 2 {
+		return nil
+	}
+
+	return p[x+1]
+}
@@ -0,0 +1,94 @@
+package page_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"github.com/sunwei/hugo-playground/resources/page"
+	"testing"
+)
+
+const sortFiles = `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/blog/a.md --
+---
+title: "Zebra"
+date: 2021-01-01
+weight: 2
+rating: 5
+cat: "x"
+---
+A long content here for length.
+-- content/blog/b.md --
+---
+title: "Äpfel"
+date: 2022-05-01
+weight: 1
+rating: 10
+cat: "y"
+---
+B
+-- content/docs/c.md --
+---
+title: "banana"
+date: 2020-01-01
+rating: 1
+cat: "x"
+---
+C c c
+-- layouts/index.html --
+HOME
+W:{{ range .Site.RegularPages.ByWeight }}{{ .Title }},{{ end }}
+T:{{ range .Site.RegularPages.ByTitle }}{{ .Title }},{{ end }}
+LT:{{ range .Site.RegularPages.ByLinkTitle.Reverse }}{{ .Title }},{{ end }}
+D:{{ range .Site.RegularPages.ByDate }}{{ .Title }},{{ end }}
+PD:{{ range .Site.RegularPages.ByPublishDate.Reverse }}{{ .Title }},{{ end }}
+LM:{{ range .Site.RegularPages.ByLastmod }}{{ .Title }},{{ end }}
+LEN:{{ range .Site.RegularPages.ByLength }}{{ .Title }},{{ end }}
+P:{{ range .Site.RegularPages.ByParam "rating" }}{{ .Title }},{{ end }}
+L:{{ range .Site.RegularPages.ByDate.Limit 2 }}{{ .Title }},{{ end }}
+GD:{{ range .Site.RegularPages.GroupByDate "2006" }}[{{ .Key }}:{{ range .Pages }}{{ .Title }},{{ end }}]{{ end }}
+GPD:{{ range .Site.RegularPages.GroupByPublishDate "2006" "asc" }}[{{ .Key }}:{{ range .Pages }}{{ .Title }},{{ end }}]{{ end }}
+GB:{{ range .Site.RegularPages.GroupBy "Section" }}[{{ .Key }}:{{ range .Pages }}{{ .Title }},{{ end }}]{{ end }}
+GP:{{ range .Site.RegularPages.GroupByParam "cat" "desc" }}[{{ .Key }}:{{ range .Pages }}{{ .Title }},{{ end }}]{{ end }}
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|N:{{ with .Site.RegularPages.ByTitle.Next . }}{{ .Title }}{{ end }}|P:{{ with .Site.RegularPages.ByTitle.Prev . }}{{ .Title }}{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+`
+
+func TestPagesSortAndGroup(t *testing.T) {
+	b := hugolib.Test(t, sortFiles)
+
+	b.AssertFileContent("index.html", `
+W:Äpfel,Zebra,banana,
+T:Äpfel,banana,Zebra,
+LT:Zebra,banana,Äpfel,
+D:banana,Zebra,Äpfel,
+PD:Äpfel,Zebra,banana,
+LM:banana,Zebra,Äpfel,
+LEN:Äpfel,banana,Zebra,
+P:banana,Zebra,Äpfel,
+L:banana,Zebra,
+GD:[2022:Äpfel,][2021:Zebra,][2020:banana,]
+GPD:[2020:banana,][2021:Zebra,][2022:Äpfel,]
+GB:[blog:Äpfel,Zebra,][docs:banana,]
+GP:[y:Äpfel,][x:Zebra,banana,]
+`)
+	b.AssertFileContent("blog/a/index.html", "SINGLE Zebra|N:banana|P:")
+	b.AssertFileContent("docs/c/index.html", "SINGLE banana|N:Äpfel|P:Zebra")
+}
+
+func TestPagesSortCacheResetOnBuild(t *testing.T) {
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: sortFiles}).Build()
+	n := page.SortCacheLen()
+	if n == 0 {
+		t.Fatal("expected cached orderings after build")
+	}
+
+	// A new build of the same project creates new pages, so anything
+	// cached from the previous build is stale.
+	b.Build()
+	if got := page.SortCacheLen(); got != n {
+		t.Fatalf("expected %d cached orderings after rebuild, got %d", n, got)
+	}
+}
@@ -0,0 +1,83 @@
+package page_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"strings"
+	"testing"
+)
+
+func TestPermalinks(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[permalinks]
+blog = "/:year/:month/:slug/"
+docs = "/d/:sections[1:]/:contentbasename/"
+news = "/:section/:year-:month-:day/:title/"
+misc = "/m/:filename/"
+[permalinks.section]
+blog = "/articles/"
+-- content/blog/post.md --
+---
+title: "Post Title"
+date: 2021-03-04
+slug: my-post
+---
+-- content/blog/other.md --
+---
+title: "Other Title"
+date: 2022-11-04T10:00:00Z
+---
+-- content/docs/a/b/c.md --
+---
+title: "C"
+---
+-- content/news/n1.md --
+---
+title: "Big News"
+date: 2020-01-02
+---
+-- content/misc/My File.md --
+---
+title: "Misc"
+---
+-- layouts/index.html --
+HOME
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ .RelPermalink }}|{{ .Permalink }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}|{{ .RelPermalink }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("2021/03/my-post/index.html", "SINGLE Post Title|/2021/03/my-post/|https://example.org/2021/03/my-post/")
+	b.AssertFileContent("2022/11/other-title/index.html", "SINGLE Other Title|/2022/11/other-title/")
+	b.AssertFileContent("articles/index.html", "LIST Blog|/articles/")
+	b.AssertFileContent("d/c/index.html", "SINGLE C|/d/c/")
+	b.AssertFileContent("news/2020-01-02/big-news/index.html", "SINGLE Big News|/news/2020-01-02/big-news/")
+	b.AssertFileContent("m/my-file/index.html", "SINGLE Misc|/m/my-file/")
+	b.AssertFileContent("docs/index.html", "LIST Docs|/docs/")
+	b.AssertFileExists("blog/post/index.html", false)
+}
+
+func TestPermalinksInvalidPattern(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[permalinks]
+blog = "/:year/:foo/"
+-- content/blog/post.md --
+---
+title: "Post"
+---
+-- layouts/_default/single.html --
+SINGLE
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil || !strings.Contains(err.Error(), "permalink ill-formed") {
+		t.Fatalf("expected ill-formed permalink error, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package page
 
 import (
+	"github.com/sunwei/hugo-playground/langs"
+	"github.com/sunwei/hugo-playground/navigation"
 	"html/template"
 )
 
@@ -13,9 +15,18 @@ type Site interface {
 	// Pages Returns all Pages in this Site.
 	Pages() Pages
 
+	// Language Returns the Language configured for this Site.
+	Language() *langs.Language
+
 	// Home A shortcut to the home page.
 	Home() Page
 
+	// GetPage Returns a page for the given ref, e.g. "/blog/my-post" or "blog".
+	GetPage(ref ...string) (Page, error)
+
+	// Menus Returns the menus for this Site.
+	Menus() navigation.Menus
+
 	// Title Returns the configured title for this Site.
 	Title() string
 
@@ -11,6 +11,12 @@ import (
 // I.e. both pages and images etc.
 type Resources []Resource
 
+// ResourcesConverter converts a given slice of Resource objects to Resources.
+type ResourcesConverter interface {
+	// For internal use.
+	ToResources() Resources
+}
+
 // Source is an internal template and not meant for use in the templates. It
 // may change without notice.
 type Source interface {
@@ -65,6 +65,24 @@ type ErrProvider interface {
 	Err() ResourceError
 }
 
+// NewResourceError creates a new ResourceError.
+func NewResourceError(err error, data any) ResourceError {
+	return &resourceError{
+		error: err,
+		data:  data,
+	}
+}
+
+type resourceError struct {
+	error
+	data any
+}
+
+// The data associated with this error.
+func (e *resourceError) Data() any {
+	return e.data
+}
+
 // ResourceError is the error return from .Err in Resource in error situations.
 type ResourceError interface {
 	error
@@ -94,6 +112,23 @@ type Identifier interface {
 	Key() string
 }
 
+// ContentResource represents a Resource that provides a way to get to its content.
+// Most Resource types in Hugo implements this interface, including Page.
+type ContentResource interface {
+	MediaType() media.Type
+	ContentProvider
+}
+
+// ResourcesLanguageMerger describes an interface for merging resources from a
+// different language.
+type ResourcesLanguageMerger interface {
+	MergeByLanguage(other Resources) Resources
+
+	// Needed for integration with the tpl package.
+	// For internal use.
+	MergeByLanguageInterface(other any) (any, error)
+}
+
 // OriginProvider provides the original Resource if this is wrapped.
 // This is an internal Hugo interface and not meant for use in the templates.
 type OriginProvider interface {
@@ -123,11 +158,22 @@ func (r resourceTypesHolder) ResourceType() string {
 	return r.resourceType
 }
 
+// LengthProvider is a Resource that provides a length
+// (typically the length of the content).
+type LengthProvider interface {
+	Len() int
+}
+
 // LanguageProvider is a Resource in a language.
 type LanguageProvider interface {
 	Language() *langs.Language
 }
 
+// TranslationKeyProvider connects translations of the same Resource.
+type TranslationKeyProvider interface {
+	TranslationKey() string
+}
+
 // UnmarshableResource represents a Resource that can be unmarshaled to some other format.
 type UnmarshableResource interface {
 	ReadSeekCloserResource
@@ -0,0 +1,175 @@
+package resources
+
+import (
+	"encoding/json"
+	"github.com/BurntSushi/locker"
+	"github.com/sunwei/hugo-playground/cache/filecache"
+	"github.com/sunwei/hugo-playground/resources/resource"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	CACHE_OTHER = "other"
+)
+
+type ResourceCache struct {
+	rs *Spec
+
+	sync.RWMutex
+
+	// Either resource.Resource or resource.Resources.
+	cache map[string]any
+
+	// Provides named resource locks.
+	nlocker *locker.Locker
+
+	// Persists transformed resources between builds.
+	fileCache *filecache.Cache
+}
+
+// ResourceCacheKey converts the filename into the format used in the resource
+// cache.
+func ResourceCacheKey(filename string) string {
+	filename = filepath.ToSlash(filename)
+	return path.Join(resourceKeyPartition(filename), filename)
+}
+
+func resourceKeyPartition(filename string) string {
+	ext := strings.TrimPrefix(path.Ext(filepath.ToSlash(filename)), ".")
+	if ext == "" {
+		ext = CACHE_OTHER
+	}
+	return ext
+}
+
+func newResourceCache(rs *Spec) *ResourceCache {
+	return &ResourceCache{
+		rs:        rs,
+		fileCache: rs.FileCaches.AssetsCache(),
+		cache:     make(map[string]any),
+		nlocker:   locker.NewLocker(),
+	}
+}
+
+func (c *ResourceCache) Contains(key string) bool {
+	key = c.cleanKey(filepath.ToSlash(key))
+	_, found := c.get(key)
+	return found
+}
+
+func (c *ResourceCache) cleanKey(key string) string {
+	return strings.TrimPrefix(path.Clean(strings.ToLower(key)), "/")
+}
+
+func (c *ResourceCache) get(key string) (any, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	r, found := c.cache[key]
+	return r, found
+}
+
+func (c *ResourceCache) GetOrCreate(key string, f func() (resource.Resource, error)) (resource.Resource, error) {
+	r, err := c.getOrCreate(key, func() (any, error) { return f() })
+	if r == nil || err != nil {
+		return nil, err
+	}
+	return r.(resource.Resource), nil
+}
+
+func (c *ResourceCache) GetOrCreateResources(key string, f func() (resource.Resources, error)) (resource.Resources, error) {
+	r, err := c.getOrCreate(key, func() (any, error) { return f() })
+	if r == nil || err != nil {
+		return nil, err
+	}
+	return r.(resource.Resources), nil
+}
+
+func (c *ResourceCache) getOrCreate(key string, f func() (any, error)) (any, error) {
+	key = c.cleanKey(key)
+	// First check in-memory cache.
+	r, found := c.get(key)
+	if found {
+		return r, nil
+	}
+	// This is a potentially long running operation, so get a named lock.
+	c.nlocker.Lock(key)
+
+	// Double check in-memory cache.
+	r, found = c.get(key)
+	if found {
+		c.nlocker.Unlock(key)
+		return r, nil
+	}
+
+	defer c.nlocker.Unlock(key)
+
+	r, err := f()
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(key, r)
+
+	return r, nil
+}
+
+func (c *ResourceCache) set(key string, r any) {
+	c.Lock()
+	defer c.Unlock()
+	c.cache[key] = r
+}
+
+func (c *ResourceCache) getFilenames(key string) (string, string) {
+	filenameMeta := key + ".json"
+	filenameContent := key + ".content"
+
+	return filenameMeta, filenameContent
+}
+
+func (c *ResourceCache) getFromFile(key string) (filecache.ItemInfo, io.ReadCloser, transformedResourceMetadata, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	var meta transformedResourceMetadata
+	filenameMeta, filenameContent := c.getFilenames(key)
+
+	_, jsonContent, _ := c.fileCache.GetBytes(filenameMeta)
+	if jsonContent == nil {
+		return filecache.ItemInfo{}, nil, meta, false
+	}
+
+	if err := json.Unmarshal(jsonContent, &meta); err != nil {
+		return filecache.ItemInfo{}, nil, meta, false
+	}
+
+	fi, rc, _ := c.fileCache.Get(filenameContent)
+
+	return fi, rc, meta, rc != nil
+}
+
+// writeMeta writes the metadata to file and returns a writer for the content part.
+func (c *ResourceCache) writeMeta(key string, meta transformedResourceMetadata) (filecache.ItemInfo, io.WriteCloser, error) {
+	filenameMeta, filenameContent := c.getFilenames(key)
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return filecache.ItemInfo{}, nil, err
+	}
+
+	_, fm, err := c.fileCache.WriteCloser(filenameMeta)
+	if err != nil {
+		return filecache.ItemInfo{}, nil, err
+	}
+	defer fm.Close()
+
+	if _, err := fm.Write(raw); err != nil {
+		return filecache.ItemInfo{}, nil, err
+	}
+
+	fi, fc, err := c.fileCache.WriteCloser(filenameContent)
+
+	return fi, fc, err
+}
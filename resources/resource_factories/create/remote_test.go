@@ -0,0 +1,103 @@
+package create_test
+
+import (
+	"fmt"
+	"github.com/sunwei/hugo-playground/hugolib"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"latest": "v1.2.3"}`)
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Disposition", `attachment; filename="notes.txt"`)
+		fmt.Fprint(w, "some notes")
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%s|%s|%s|%s|%s", r.Method, body, r.Header.Get("Authorization"), strings.Join(r.Header.Values("X-Multi"), ","), r.Header.Get("User-Agent"))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, "try later")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetRemote(t *testing.T) {
+	srv := newTestServer(t)
+
+	files := strings.ReplaceAll(`
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+{{ with resources.GetRemote "BASE/data.json" }}JSON {{ .MediaType }}|{{ (transform.Unmarshal .).latest }}|{{ strings.HasPrefix .RelPermalink "/data_" }}{{ end }}
+{{ with resources.GetRemote "BASE/download" }}DISPOSITION {{ .MediaType }}|{{ .Content }}|{{ strings.HasPrefix .RelPermalink "/notes_" }}{{ end }}
+{{ with resources.GetRemote "BASE/echo" (dict "method" "post" "body" "hello" "headers" (dict "Authorization" "Bearer t" "X-Multi" (slice "a" "b"))) }}ECHO {{ .Content }}{{ end }}
+{{ with resources.GetRemote "BASE/fail" }}{{ with .Err }}FAIL {{ . }}|{{ .Data.StatusCode }}|{{ .Data.Body }}{{ end }}{{ end }}
+{{ with resources.GetRemote "BASE/missing" }}FOUND{{ else }}NOTFOUND{{ end }}
+{{ with resources.GetRemote "ftp://example.org/a.txt" }}{{ with .Err }}SCHEME {{ . }}{{ end }}{{ end }}
+`, "BASE", srv.URL)
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+JSON application/json|v1.2.3|true
+DISPOSITION text/plain|some notes|true
+ECHO POST|hello|Bearer t|a,b|Hugo Static Site Generator
+FAIL failed to fetch remote resource: Service Unavailable|503|try later
+NOTFOUND
+SCHEME error calling resources.GetRemote: unsupported URL scheme &#34;ftp&#34;
+`)
+}
+
+func TestGetRemoteOffline(t *testing.T) {
+	srv := newTestServer(t)
+
+	files := strings.ReplaceAll(`
+-- config.toml --
+baseURL = "https://example.org/"
+[caches.getresource]
+maxAge = "1h"
+-- layouts/index.html --
+{{ with resources.GetRemote "BASE/data.json" }}{{ with .Err }}ERR {{ . }}{{ else }}JSON {{ (transform.Unmarshal .).latest }}{{ end }}{{ end }}
+`, "BASE", srv.URL)
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files}).Build()
+	b.AssertFileContent("index.html", "JSON v1.2.3")
+
+	// Go offline and let the cached copy expire.
+	srv.Close()
+	old := time.Now().Add(-2 * time.Hour)
+	cacheDir := filepath.Join(b.Cfg.WorkingDir, "_cache")
+	if err := filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, old, old)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Build()
+	b.AssertFileContent("index.html", "JSON v1.2.3")
+	b.AssertLogContains("using the cached copy")
+
+	// Nothing cached to fall back to.
+	b = hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files}).Build()
+	b.AssertFileContent("index.html", "ERR error calling resources.GetRemote: Get")
+}
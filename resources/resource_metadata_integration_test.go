@@ -0,0 +1,57 @@
+package resources_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestResourceMetadata(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/gallery/index.md --
+---
+title: "Gallery"
+resources:
+- src: "sunset.jpg"
+  name: "header"
+  title: "The Sunset"
+  params:
+    Credits: "Alice"
+- src: "**.jpg"
+  name: "photo-:counter"
+  title: "Photo #:counter"
+  params:
+    credits: "Bob"
+    caption: "Default caption"
+- src: "*.txt"
+  title: "Notes :counter"
+---
+-- content/gallery/sunset.jpg --
+S
+-- content/gallery/a.jpg --
+A
+-- content/gallery/b.jpg --
+B
+-- content/gallery/readme.txt --
+R
+-- content/gallery/plain.pdf --
+P
+-- layouts/_default/single.html --
+{{ range .Resources }}{{ .Name }}|{{ .Title }}|{{ .Params.credits }}|{{ .Params.caption }}|{{ .RelPermalink }}
+{{ end }}{{ with .Resources.GetMatch "photo-*" }}FIRST {{ .Name }}{{ end }}
+-- layouts/_default/list.html --
+LIST
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("gallery/index.html", `
+header|The Sunset|Alice|Default caption|/gallery/sunset.jpg
+photo-1|Photo #1|Bob|Default caption|/gallery/a.jpg
+photo-2|Photo #2|Bob|Default caption|/gallery/b.jpg
+plain.pdf|plain.pdf|||/gallery/plain.pdf
+readme.txt|Notes 1|||/gallery/readme.txt
+FIRST photo-1
+`)
+}
@@ -1,9 +1,23 @@
 package resources
 
 import (
+	"errors"
+	"fmt"
+	"github.com/spf13/afero"
+	"github.com/sunwei/hugo-playground/cache/filecache"
+	"github.com/sunwei/hugo-playground/common/loggers"
 	"github.com/sunwei/hugo-playground/helpers"
+	"github.com/sunwei/hugo-playground/hugofs"
 	"github.com/sunwei/hugo-playground/media"
 	"github.com/sunwei/hugo-playground/output"
+	"github.com/sunwei/hugo-playground/resources/images"
+	"github.com/sunwei/hugo-playground/resources/page"
+	"github.com/sunwei/hugo-playground/resources/resource"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
 type Spec struct {
@@ -11,18 +25,207 @@ type Spec struct {
 
 	MediaTypes    media.Types
 	OutputFormats output.Formats
+
+	Logger loggers.Logger
+
+	Permalinks page.PermalinkExpander
+
+	// Holds default filter settings etc.
+	imaging *images.ImageProcessor
+
+	imageCache    *imageCache
+	ResourceCache *ResourceCache
+	FileCaches    filecache.Caches
 }
 
 func NewSpec(
 	s *helpers.PathSpec,
+	fileCaches filecache.Caches,
+	logger loggers.Logger,
 	outputFormats output.Formats,
 	mimeTypes media.Types) (*Spec, error) {
+	imgConfig, err := images.DecodeConfig(s.Cfg.GetStringMap("imaging"))
+	if err != nil {
+		return nil, err
+	}
+
+	imaging, err := images.NewImageProcessor(imgConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = loggers.NewErrorLogger()
+	}
+
+	permalinks, err := page.NewPermalinkExpander(s)
+	if err != nil {
+		return nil, err
+	}
 
 	rs := &Spec{
 		PathSpec:      s,
+		Logger:        logger,
+		imaging:       imaging,
 		MediaTypes:    mimeTypes,
 		OutputFormats: outputFormats,
+		Permalinks:    permalinks,
+		FileCaches:    fileCaches,
+		imageCache: newImageCache(
+			fileCaches.ImageCache(),
+			s,
+		),
 	}
 
+	rs.ResourceCache = newResourceCache(rs)
+
 	return rs, nil
 }
+
+func (r *Spec) New(fd ResourceSourceDescriptor) (resource.Resource, error) {
+	return r.newResourceFor(fd)
+}
+
+func (r *Spec) newGenericResourceWithBase(
+	sourceFs afero.Fs,
+	openReadSeekerCloser resource.OpenReadSeekCloser,
+	targetPathBaseDirs []string,
+	targetPathBuilder func() page.TargetPaths,
+	osFileInfo os.FileInfo,
+	sourceFilename,
+	baseFilename string,
+	mediaType media.Type) *genericResource {
+	if osFileInfo != nil && osFileInfo.IsDir() {
+		panic(fmt.Sprintf("dirs not supported resource types: %v", osFileInfo))
+	}
+
+	// This value is used both to construct URLs and file paths, but start
+	// with a Unix-styled path.
+	baseFilename = helpers.ToSlashTrimLeading(baseFilename)
+	fpath, fname := path.Split(baseFilename)
+
+	resourceType := mediaType.MainType
+
+	pathDescriptor := &resourcePathDescriptor{
+		baseTargetPathDirs: helpers.UniqueStringsReuse(targetPathBaseDirs),
+		targetPathBuilder:  targetPathBuilder,
+		relTargetDirFile:   dirFile{dir: fpath, file: fname},
+	}
+
+	var fim hugofs.FileMetaInfo
+	if osFileInfo != nil {
+		fim = osFileInfo.(hugofs.FileMetaInfo)
+	}
+
+	gfi := &resourceFileInfo{
+		fi:                   fim,
+		openReadSeekerCloser: openReadSeekerCloser,
+		sourceFs:             sourceFs,
+		sourceFilename:       sourceFilename,
+		h:                    &resourceHash{},
+	}
+
+	g := &genericResource{
+		resourceFileInfo:       gfi,
+		resourcePathDescriptor: pathDescriptor,
+		mediaType:              mediaType,
+		resourceType:           resourceType,
+		spec:                   r,
+		params:                 make(map[string]any),
+		name:                   baseFilename,
+		title:                  baseFilename,
+		resourceContent:        &resourceContent{},
+	}
+
+	return g
+}
+
+func (r *Spec) newResource(sourceFs afero.Fs, fd ResourceSourceDescriptor) (resource.Resource, error) {
+	fi := fd.FileInfo
+	var sourceFilename string
+
+	if fd.OpenReadSeekCloser != nil {
+	} else if fd.SourceFilename != "" {
+		var err error
+		fi, err = sourceFs.Stat(fd.SourceFilename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		sourceFilename = fd.SourceFilename
+	} else {
+		sourceFilename = fd.SourceFile.Filename()
+	}
+
+	if fd.RelTargetFilename == "" {
+		fd.RelTargetFilename = sourceFilename
+	}
+
+	mimeType := fd.MediaType
+	if mimeType.IsZero() {
+		ext := strings.ToLower(filepath.Ext(fd.RelTargetFilename))
+		var (
+			found      bool
+			suffixInfo media.SuffixInfo
+		)
+		mimeType, suffixInfo, found = r.MediaTypes.GetFirstBySuffix(strings.TrimPrefix(ext, "."))
+		// TODO(bep) we need to handle these ambiguous types better, but in this context
+		// we most likely want the application/xml type.
+		if suffixInfo.Suffix == "xml" && mimeType.SubType == "rss" {
+			mimeType, found = r.MediaTypes.GetByType("application/xml")
+		}
+
+		if !found {
+			// A fallback. Note that mime.TypeByExtension is slow by Hugo standards,
+			// so we should configure media types to avoid this lookup for most
+			// situations.
+			mimeStr := mime.TypeByExtension(ext)
+			if mimeStr != "" {
+				mimeType, _ = media.FromStringAndExt(mimeStr, ext)
+			}
+		}
+	}
+
+	gr := r.newGenericResourceWithBase(
+		sourceFs,
+		fd.OpenReadSeekCloser,
+		fd.TargetBasePaths,
+		fd.TargetPaths,
+		fi,
+		sourceFilename,
+		fd.RelTargetFilename,
+		mimeType)
+
+	if mimeType.MainType == "image" {
+		imgFormat, ok := images.ImageFormatFromMediaSubType(mimeType.SubType)
+		if ok {
+			ir := &imageResource{
+				Image:        images.NewImage(imgFormat, r.imaging, nil, gr),
+				baseResource: gr,
+			}
+			ir.root = ir
+			return newResourceAdapter(gr.spec, fd.LazyPublish, ir), nil
+		}
+
+	}
+
+	return newResourceAdapter(gr.spec, fd.LazyPublish, gr), nil
+}
+
+func (r *Spec) newResourceFor(fd ResourceSourceDescriptor) (resource.Resource, error) {
+	if fd.OpenReadSeekCloser == nil {
+		if fd.SourceFile != nil && fd.SourceFilename != "" {
+			return nil, errors.New("both SourceFile and AbsSourceFilename provided")
+		} else if fd.SourceFile == nil && fd.SourceFilename == "" {
+			return nil, errors.New("either SourceFile or AbsSourceFilename must be provided")
+		}
+	}
+
+	if fd.RelTargetFilename == "" {
+		fd.RelTargetFilename = fd.Filename()
+	}
+
+	return r.newResource(fd.Fs, fd)
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package css
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/sunwei/hugo-playground/common/text"
+	"github.com/sunwei/hugo-playground/hugofs"
+	"github.com/sunwei/hugo-playground/hugolib/filesystems"
+	"github.com/sunwei/hugo-playground/media"
+	"github.com/sunwei/hugo-playground/minifiers"
+	"github.com/sunwei/hugo-playground/resources"
+	"github.com/sunwei/hugo-playground/resources/internal"
+	"github.com/sunwei/hugo-playground/resources/resource"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Client context for bundling CSS.
+type Client struct {
+	rs  *resources.Spec
+	sfs *filesystems.SourceFilesystem
+	m   minifiers.Client
+}
+
+// New creates a new client context.
+func New(fs *filesystems.SourceFilesystem, rs *resources.Spec) (*Client, error) {
+	m, err := minifiers.New(rs.MediaTypes, rs.OutputFormats, rs.Cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		rs:  rs,
+		sfs: fs,
+		m:   m,
+	}, nil
+}
+
+type buildTransformation struct {
+	optsm map[string]any
+	c     *Client
+}
+
+func (t *buildTransformation) Key() internal.ResourceTransformationKey {
+	return internal.NewResourceTransformationKey("cssbuild", t.optsm)
+}
+
+func (t *buildTransformation) Transform(ctx *resources.ResourceTransformationCtx) error {
+	ctx.OutMediaType = media.CSSType
+
+	opts, err := decodeOptions(t.optsm)
+	if err != nil {
+		return err
+	}
+
+	if opts.TargetPath != "" {
+		ctx.OutPath = opts.TargetPath
+	} else {
+		ctx.ReplaceOutPathExtension(".css")
+	}
+
+	src, err := ioutil.ReadAll(ctx.From)
+	if err != nil {
+		return err
+	}
+
+	opts.sourcefile = filepath.FromSlash(ctx.SourcePath)
+	opts.sourceDir = filepath.FromSlash(path.Dir(ctx.SourcePath))
+	opts.resolveDir = t.c.rs.WorkingDir
+	opts.contents = string(src)
+	opts.outPath = ctx.OutPath
+
+	// Files referenced in url() are published next to the bundle, once.
+	published := make(map[string]bool)
+	publish := func(target string, m *hugofs.FileMeta) error {
+		if published[target] {
+			return nil
+		}
+		published[target] = true
+
+		f, err := m.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w, err := ctx.OpenResourcePublisher(target)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	buildOptions := toBuildOptions(opts)
+	buildOptions.Plugins = createBuildPlugins(t.c, opts, publish)
+
+	result := api.Build(buildOptions)
+
+	if len(result.Errors) > 0 {
+
+		createErr := func(msg api.Message) error {
+			loc := msg.Location
+			if loc == nil {
+				return errors.New(msg.Text)
+			}
+			errorMessage := msg.Text
+			errorMessage = strings.ReplaceAll(errorMessage, nsImportHugo+":", "")
+
+			// Both the entry and the files in ns-hugo are relative to /assets.
+			path := t.c.sfs.RealFilename(strings.TrimPrefix(loc.File, nsImportHugo+":"))
+
+			pos := text.Position{Filename: path, Offset: -1, LineNumber: loc.Line, ColumnNumber: loc.Column}
+
+			return fmt.Errorf("%s: %s", pos, errorMessage)
+		}
+
+		var errors []error
+
+		for _, msg := range result.Errors {
+			errors = append(errors, createErr(msg))
+		}
+
+		// Return 1, log the rest.
+		for i, err := range errors {
+			if i > 0 {
+				t.c.rs.Logger.Errorf("css.Build failed: %s", err)
+			}
+		}
+
+		return errors[0]
+	}
+
+	content := result.OutputFiles[0].Contents
+
+	if opts.Minify {
+		return t.c.m.Minify(media.CSSType, ctx.To, bytes.NewReader(content))
+	}
+
+	_, err = ctx.To.Write(content)
+	return err
+}
+
+// Process bundles the given CSS resource and its imports into one stylesheet.
+func (c *Client) Process(res resources.ResourceTransformer, opts map[string]any) (resource.Resource, error) {
+	return res.Transform(
+		&buildTransformation{c: c, optsm: opts},
+	)
+}
@@ -0,0 +1,238 @@
+// Copyright 2020 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package css
+
+import (
+	"fmt"
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+	"github.com/sunwei/hugo-playground/helpers"
+	"github.com/sunwei/hugo-playground/hugofs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	nsImportHugo = "ns-hugo"
+)
+
+// Options for the CSS bundler.
+type Options struct {
+	// If not set, the source path will be used as the base target path.
+	TargetPath string
+
+	// Whether to minify the output using the site's CSS minifier.
+	Minify bool
+
+	// Keep nested rules as written. The default is to lower CSS nesting to
+	// flat rules, which is understood by all browsers.
+	PreserveNesting bool
+
+	contents   string
+	sourcefile string
+	sourceDir  string
+	resolveDir string
+	outPath    string
+}
+
+func decodeOptions(m map[string]any) (Options, error) {
+	var opts Options
+
+	if err := mapstructure.WeakDecode(m, &opts); err != nil {
+		return opts, err
+	}
+
+	if opts.TargetPath != "" {
+		opts.TargetPath = helpers.ToSlashTrimLeading(opts.TargetPath)
+	}
+
+	return opts, nil
+}
+
+// isExternalURL reports whether the given url() or @import reference points
+// outside of /assets, e.g. to a CDN, a data URL or an absolute site path.
+func isExternalURL(s string) bool {
+	return s == "" ||
+		strings.HasPrefix(s, "#") ||
+		strings.HasPrefix(s, "/") ||
+		strings.HasPrefix(s, "data:") ||
+		strings.Contains(s, "://")
+}
+
+// splitURLSuffix splits off any query string or fragment, e.g. as used in
+// "font.woff2?v=2" or "icons.svg#home".
+func splitURLSuffix(s string) (string, string) {
+	if i := strings.IndexAny(s, "?#"); i != -1 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// resolveComponentInAssets resolves impPath relative to relDir and then
+// relative to the root of /assets, returning the path relative to /assets
+// and its file meta, or nil if not found.
+func resolveComponentInAssets(fs afero.Fs, relDir, impPath string, exts ...string) (string, *hugofs.FileMeta) {
+	impPath = filepath.FromSlash(impPath)
+
+	candidates := []string{filepath.Join(relDir, impPath)}
+	if !strings.HasPrefix(impPath, ".") {
+		candidates = append(candidates, filepath.Clean(impPath))
+	}
+
+	for _, candidate := range candidates {
+		for _, ext := range append([]string{""}, exts...) {
+			filename := candidate + ext
+			if strings.HasPrefix(filename, "..") {
+				// Outside of /assets.
+				continue
+			}
+			if fi, err := fs.Stat(filename); err == nil && !fi.IsDir() {
+				return filepath.ToSlash(filename), fi.(hugofs.FileMetaInfo).Meta()
+			}
+		}
+	}
+
+	// Not found.
+	return "", nil
+}
+
+func createBuildPlugins(c *Client, opts Options, publish func(target string, m *hugofs.FileMeta) error) []api.Plugin {
+	fs := c.rs.Assets
+
+	// ESBuild places the stdin entry at this path in the file namespace.
+	stdinImporter := filepath.Join(opts.resolveDir, opts.sourcefile)
+
+	importerDir := func(args api.OnResolveArgs) (string, bool) {
+		if args.Namespace == nsImportHugo {
+			// Already relative to /assets.
+			return filepath.Dir(filepath.FromSlash(args.Importer)), true
+		}
+		if args.Importer == stdinImporter {
+			return opts.sourceDir, true
+		}
+		rel, found := fs.MakePathRelative(args.Importer)
+		if !found {
+			return "", false
+		}
+		return filepath.Dir(rel), true
+	}
+
+	resolveImport := func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+		if isExternalURL(args.Path) {
+			return api.OnResolveResult{Path: args.Path, External: true}, nil
+		}
+
+		relDir, found := importerDir(args)
+		if !found {
+			// Let ESBuild resolve this.
+			return api.OnResolveResult{}, nil
+		}
+
+		// Use the path relative to /assets so no absolute filenames
+		// end up in the comments of the bundle.
+		if rel, m := resolveComponentInAssets(fs.Fs, relDir, args.Path, ".css"); m != nil {
+			return api.OnResolveResult{Path: rel, Namespace: nsImportHugo}, nil
+		}
+
+		// Fall back to ESBuild's resolve.
+		return api.OnResolveResult{}, nil
+	}
+
+	resolveURL := func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+		// Anything we cannot find in /assets is left as is.
+		unchanged := api.OnResolveResult{Path: args.Path, External: true}
+
+		if isExternalURL(args.Path) {
+			return unchanged, nil
+		}
+
+		relDir, found := importerDir(args)
+		if !found {
+			return unchanged, nil
+		}
+
+		name, suffix := splitURLSuffix(args.Path)
+		target, m := resolveComponentInAssets(fs.Fs, relDir, name)
+		if m == nil {
+			return unchanged, nil
+		}
+
+		if err := publish(target, m); err != nil {
+			return api.OnResolveResult{}, err
+		}
+
+		// Make the reference relative to where the bundle gets published.
+		rel, err := filepath.Rel(filepath.FromSlash(path.Dir(opts.outPath)), filepath.FromSlash(target))
+		if err != nil {
+			return api.OnResolveResult{}, err
+		}
+
+		return api.OnResolveResult{Path: filepath.ToSlash(rel) + suffix, External: true}, nil
+	}
+
+	importResolver := api.Plugin{
+		Name: "hugo-css-import-resolver",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `.*`},
+				func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+					switch args.Kind {
+					case api.ResolveCSSImportRule:
+						return resolveImport(args)
+					case api.ResolveCSSURLToken:
+						return resolveURL(args)
+					}
+					return api.OnResolveResult{}, nil
+				})
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: nsImportHugo},
+				func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+					b, err := afero.ReadFile(fs.Fs, filepath.FromSlash(args.Path))
+					if err != nil {
+						return api.OnLoadResult{}, fmt.Errorf("failed to read %q: %w", args.Path, err)
+					}
+					c := string(b)
+					return api.OnLoadResult{
+						ResolveDir: opts.resolveDir,
+						Contents:   &c,
+						Loader:     api.LoaderCSS,
+					}, nil
+				})
+		},
+	}
+
+	return []api.Plugin{importResolver}
+}
+
+func toBuildOptions(opts Options) api.BuildOptions {
+	var supported map[string]bool
+	if !opts.PreserveNesting {
+		supported = map[string]bool{"nesting": false}
+	}
+
+	return api.BuildOptions{
+		Bundle:    true,
+		Supported: supported,
+
+		// Paths in comments are relative to this.
+		AbsWorkingDir: opts.resolveDir,
+
+		Stdin: &api.StdinOptions{
+			Contents:   opts.contents,
+			Sourcefile: opts.sourcefile,
+			ResolveDir: opts.resolveDir,
+			Loader:     api.LoaderCSS,
+		},
+	}
+}
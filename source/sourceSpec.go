@@ -82,6 +82,8 @@ func (sp *SourceSpec) NewFileInfo(fi hugofs.FileMetaInfo) (*FileInfo, error) {
 		relDir = relDir + helpers.FilePathSeparator
 	}
 
+	translationBaseName := m.TranslationBaseName
+
 	dir, name := filepath.Split(relPath)
 	if !strings.HasSuffix(dir, helpers.FilePathSeparator) {
 		dir = dir + helpers.FilePathSeparator
@@ -90,6 +92,14 @@ func (sp *SourceSpec) NewFileInfo(fi hugofs.FileMetaInfo) (*FileInfo, error) {
 	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
 	baseName := paths.Filename(name)
 
+	if translationBaseName == "" {
+		// This is usually provided by the filesystem, but we don't
+		// have any language handling in place yet, so this is an
+		// approximate implementation that strips any language extension.
+		fileLangExt := filepath.Ext(baseName)
+		translationBaseName = strings.TrimSuffix(baseName, fileLangExt)
+	}
+
 	f := &FileInfo{
 		sp:         sp,
 		filename:   filename,
@@ -101,6 +111,8 @@ func (sp *SourceSpec) NewFileInfo(fi hugofs.FileMetaInfo) (*FileInfo, error) {
 		name:       name,
 		baseName:   baseName, // BaseFileName()
 		classifier: m.Classifier,
+
+		translationBaseName: translationBaseName,
 	}
 
 	return f, nil
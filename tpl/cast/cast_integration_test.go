@@ -0,0 +1,19 @@
+package cast_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestCast(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+C:{{ int "42" }}|{{ float "1.5" }}|{{ string 42 }}|{{ add (int "3") 1 }}|{{ int 3.9 }}|{{ printf "%T" (float 2) }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "C:42|1.5|42|4|3|float64")
+}
@@ -0,0 +1,102 @@
+package collections_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestCollections(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/blog/a.md --
+---
+title: "A"
+date: 2021-01-01
+tags: ["go", "hugo"]
+rating: 5
+nested:
+  color: "red"
+---
+-- content/blog/b.md --
+---
+title: "B"
+date: 2022-01-01
+tags: ["rust"]
+rating: 10
+nested:
+  color: "blue"
+---
+-- content/docs/c.md --
+---
+title: "C"
+date: 2020-01-01
+tags: ["go"]
+rating: 1
+---
+-- layouts/index.html --
+LEN:{{ len .Site.RegularPages }}
+W1:{{ range where .Site.RegularPages "Section" "blog" }}{{ .Title }},{{ end }}
+W2:{{ range where .Site.RegularPages "Params.rating" ">=" 5 }}{{ .Title }},{{ end }}
+W3:{{ range where .Site.RegularPages "Params.tags" "intersect" (slice "go") }}{{ .Title }},{{ end }}
+W4:{{ range where .Site.RegularPages "Title" "in" (slice "A" "C") }}{{ .Title }},{{ end }}
+W5:{{ range where .Site.RegularPages "Title" "like" "^[AB]" }}{{ .Title }},{{ end }}
+W6:{{ range where .Site.RegularPages ".Params.nested.color" "blue" }}{{ .Title }},{{ end }}
+W7:{{ range where .Site.RegularPages "Section" "!=" "blog" }}{{ .Title }},{{ end }}
+F:{{ range first 2 .Site.RegularPages.ByTitle }}{{ .Title }},{{ end }}
+L:{{ range last 1 .Site.RegularPages.ByTitle }}{{ .Title }},{{ end }}
+AF:{{ range after 1 .Site.RegularPages.ByTitle }}{{ .Title }},{{ end }}
+S:{{ range sort .Site.RegularPages "Params.rating" "desc" }}{{ .Title }},{{ end }}
+S2:{{ sort (slice 3 1 2) }} {{ sort (dict "b" 2 "a" 1) }}
+U:{{ uniq (slice 1 2 2 3) }} UN:{{ union (slice 1 2) (slice 2 3) }} I:{{ intersect (slice 1 2) (slice 2 3) }}
+C:{{ complement (slice 1) (slice 1 2 3) }} SD:{{ symdiff (slice 1 2) (slice 2 3) }}
+AP:{{ apply (slice (slice "a" "b") (slice "c")) "delimit" "." "-" }}
+SEQ:{{ seq 3 }} {{ seq 2 5 }}
+D:{{ $d := dict "a" 1 "b" (dict "c" 2) }}{{ $d.a }} {{ index $d "b" "c" }}
+APP:{{ append 3 (slice 1 2) }} {{ append (slice 4 5) (slice 1 2) }}
+M:{{ merge (dict "a" 1 "b" 2) (dict "b" 3 "c" 4) }}
+SH:{{ len (shuffle (slice 1 2 3)) }}
+IS:{{ isset (dict "a" 1) "a" }} {{ isset (slice 1 2) 5 }}
+IN:{{ in (slice "a" "b") "b" }} {{ in "hugo rocks" "rocks" }}
+DL:{{ delimit (slice "a" "b" "c") ", " " and " }}
+Q:{{ querify "a" 1 "b" "x y" }}
+G:{{ range (.Site.RegularPages.GroupBy "Section") }}{{ .Key }}{{ end }} {{ with group "k" .Site.RegularPages }}{{ .Key }}={{ len .Pages }}{{ end }}
+KV:{{ range .Site.RegularPages.RelatedTo (keyVals "tags" "go") }}{{ .Title }},{{ end }}
+-- layouts/_default/single.html --
+SINGLE
+-- layouts/_default/list.html --
+LIST
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+LEN:3
+W1:B,A,
+W2:B,A,
+W3:A,C,
+W4:A,C,
+W5:B,A,
+W6:B,
+W7:C,
+F:A,B,
+L:C,
+AF:B,C,
+S:B,A,C,
+S2:[1 2 3] [1 2]
+U:[1 2 3] UN:[1 2 3] I:[2]
+C:[2 3] SD:[3 1]
+AP:[a-b c]
+SEQ:[1 2 3] [2 3 4 5]
+D:1 2
+APP:[1 2 3] [1 2 4 5]
+M:map[a:1 b:3 c:4]
+SH:3
+IS:true false
+IN:true true
+DL:a, b and c
+Q:a=1&amp;b=x&#43;y
+G:blogdocs k=3
+KV:A,C,
+`)
+}
@@ -0,0 +1,43 @@
+package crypto_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestCrypto(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+md5: {{ md5 "Hello world, gophers!" }}
+sha1: {{ sha1 "Hello world, gophers!" }}
+sha256: {{ sha256 "x" }}
+hmac: {{ hmac "sha256" "Secret key" "Hello world, gophers!" }}
+hmacBinary: {{ len (hmac "md5" "Secret key" "x" "binary") }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+md5: b3029f756f98f79e7f1b7f1d1f0dd53b
+sha1: c8b5b0e33d408246e30f53e32b8f7627a7a649d4
+sha256: 2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881
+hmac: b6d11b6c53830b9d87036272ca9fe9d19306b8f9d8aa07b15da27d89e6e34f40
+hmacBinary: 16
+`)
+}
+
+func TestCryptoHMACInvalidHash(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+{{ hmac "sha3" "k" "m" }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	if err := b.BuildE(); err == nil {
+		t.Fatal("expected an unsupported hash function to fail the build")
+	}
+}
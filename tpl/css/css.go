@@ -0,0 +1,72 @@
+// Copyright 2020 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package css provides functions for bundling CSS resources.
+package css
+
+import (
+	"github.com/sunwei/hugo-playground/deps"
+	"github.com/sunwei/hugo-playground/resources"
+	"github.com/sunwei/hugo-playground/resources/resource"
+	"github.com/sunwei/hugo-playground/resources/resource_transformers/css"
+	"github.com/sunwei/hugo-playground/tpl/internal/resourcehelpers"
+)
+
+// New returns a new instance of the css-namespaced template functions.
+func New(deps *deps.Deps) (*Namespace, error) {
+	if deps.ResourceSpec == nil {
+		return &Namespace{}, nil
+	}
+
+	client, err := css.New(deps.BaseFs.Assets, deps.ResourceSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Namespace{
+		deps:   deps,
+		client: client,
+	}, nil
+}
+
+// Namespace provides template functions for the "css" namespace.
+type Namespace struct {
+	deps   *deps.Deps
+	client *css.Client
+}
+
+// Build bundles the given CSS Resource, inlining its @import rules.
+func (ns *Namespace) Build(args ...any) (resource.Resource, error) {
+	var (
+		r          resources.ResourceTransformer
+		m          map[string]any
+		targetPath string
+		err        error
+		ok         bool
+	)
+
+	r, targetPath, ok = resourcehelpers.ResolveIfFirstArgIsString(args)
+
+	if !ok {
+		r, m, err = resourcehelpers.ResolveArgs(args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if targetPath != "" {
+		m = map[string]any{"targetPath": targetPath}
+	}
+
+	return ns.client.Process(r, m)
+}
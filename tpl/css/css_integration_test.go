@@ -0,0 +1,107 @@
+package css_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- assets/css/main.css --
+@import "https://fonts.example.com/inter.css";
+@import "base/reset.css";
+@import "./partials/card";
+.hero { background: url("../img/bg.png"); }
+.nav {
+  color: red;
+  & a { color: blue; }
+}
+.logo { background: url(data:image/gif;base64,R0lGOD==); }
+.abs { background: url(/images/abs.png); }
+.missing { background: url(nope.png); }
+@font-face { font-family: X; src: url("fonts/x.woff2?v=2") format("woff2"); }
+-- assets/base/reset.css --
+* { margin: 0; }
+-- assets/css/partials/card.css --
+.card { background: url("../../img/icon.svg#home"); }
+.card { .title { font-weight: bold; } }
+-- assets/img/bg.png --
+PNG
+-- assets/img/icon.svg --
+<svg/>
+-- assets/fonts/x.woff2 --
+WOFF
+-- layouts/index.html --
+{{ with resources.Get "css/main.css" | css.Build }}A {{ .RelPermalink }}|{{ .MediaType }}{{ end }}
+{{ with resources.Get "css/main.css" | css.Build (dict "targetPath" "styles/site.css" "minify" true) }}B {{ .RelPermalink }}|{{ .Content | safeHTML }}{{ end }}
+{{ with resources.Get "css/partials/card.css" | css.Build (dict "preserveNesting" true) }}C {{ .RelPermalink }}{{ end }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+A /css/main.css|text/css
+B /styles/site.css|@import "https://fonts.example.com/inter.css";*{margin:0}.card{background:url(../img/icon.svg#home)}
+C /css/partials/card.css
+`)
+
+	b.AssertFileContent("css/main.css", `
+@import "https://fonts.example.com/inter.css";
+/* ns-hugo:base/reset.css */
+/* ns-hugo:css/partials/card.css */
+background: url(../img/icon.svg#home);
+/* css/main.css */
+background: url(../img/bg.png);
+.nav a {
+background: url(data:image/gif;base64,R0lGOD==);
+background: url(/images/abs.png);
+background: url(nope.png);
+src: url(../fonts/x.woff2?v=2) format("woff2");
+`)
+	b.AssertFileContent("css/partials/card.css", `
+background: url(../../img/icon.svg#home);
+.title {
+`)
+
+	b.AssertFileContent("img/bg.png", "PNG")
+	b.AssertFileContent("img/icon.svg", "<svg/>")
+	b.AssertFileContent("fonts/x.woff2", "WOFF")
+	b.AssertFileExists("nope.png", false)
+
+	// No filenames from the build machine end up in the published files.
+	wd := filepath.ToSlash(b.Cfg.WorkingDir)
+	for _, filename := range []string{"css/main.css", "styles/site.css", "css/partials/card.css"} {
+		if content := b.FileContent(filename); strings.Contains(content, wd) {
+			t.Errorf("%s contains the working dir %q", filename, wd)
+		}
+	}
+}
+
+func TestBuildError(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- assets/css/main.css --
+@import "partials/card.css";
+-- assets/css/partials/card.css --
+/* Card. */
+@import "missing.css";
+-- layouts/index.html --
+{{ (resources.Get "css/main.css" | css.Build).Content }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expect := filepath.Join(b.Cfg.WorkingDir, "assets", "css", "partials", "card.css") + `:2:8": Could not resolve "missing.css"`
+	if !strings.Contains(err.Error(), expect) {
+		t.Fatalf("got error %q, want %q", err, expect)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encoding provides template functions for encoding content.
+package encoding
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+	bp "github.com/sunwei/hugo-playground/bufferpool"
+)
+
+// New returns a new instance of the encoding-namespaced template functions.
+func New() *Namespace {
+	return &Namespace{}
+}
+
+// Namespace provides template functions for the "encoding" namespace.
+type Namespace struct{}
+
+// Base64Decode returns the base64 decoding of the given content.
+func (ns *Namespace) Base64Decode(content any) (string, error) {
+	conv, err := cast.ToStringE(content)
+	if err != nil {
+		return "", err
+	}
+
+	dec, err := base64.StdEncoding.DecodeString(conv)
+	return string(dec), err
+}
+
+// Base64Encode returns the base64 encoding of the given content.
+func (ns *Namespace) Base64Encode(content any) (string, error) {
+	conv, err := cast.ToStringE(content)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(conv)), nil
+}
+
+type jsonifyOpts struct {
+	Prefix       string
+	Indent       string
+	NoHTMLEscape bool
+}
+
+// Jsonify encodes a given object to JSON.  To pretty print the JSON, pass a map
+// or dictionary of options as the first argument.  Supported options are
+// "prefix" and "indent".  Each JSON element in the output will begin on a new
+// line beginning with prefix followed by one or more copies of indent according
+// to the indentation nesting. Set "noHTMLEscape" to true to keep <, > and &
+// as-is instead of escaping them to \u003c etc.
+func (ns *Namespace) Jsonify(args ...any) (template.HTML, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	switch len(args) {
+	case 0:
+		return "", nil
+	case 1:
+		b, err = json.Marshal(args[0])
+	case 2:
+		var opts jsonifyOpts
+		if err = mapstructure.WeakDecode(args[0], &opts); err != nil {
+			break
+		}
+
+		buff := bp.GetBuffer()
+		defer bp.PutBuffer(buff)
+		enc := json.NewEncoder(buff)
+		enc.SetEscapeHTML(!opts.NoHTMLEscape)
+		enc.SetIndent(opts.Prefix, opts.Indent)
+		if err = enc.Encode(args[1]); err != nil {
+			break
+		}
+		// Encode adds a trailing newline, json.Marshal does not.
+		b = bytes.TrimSuffix(buff.Bytes(), []byte("\n"))
+	default:
+		err = errors.New("too many arguments to jsonify")
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(b), nil
+}
@@ -0,0 +1,33 @@
+package encoding_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+base64: {{ base64Encode "Hugo" }}|{{ base64Decode "SHVnbw==" }}|{{ base64Encode 42 }}
+jsonify: {{ jsonify (dict "a" "<b>" "c" 1) }}
+noHTMLEscape: {{ jsonify (dict "noHTMLEscape" true) (dict "a" "<b>") }}
+indent: {{ jsonify (dict "indent" "  ") (dict "a" 1) }}
+prefix: {{ jsonify (dict "prefix" "//" "indent" "  ") (slice 1) }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+base64: SHVnbw==|Hugo|NDI=
+jsonify: {"a":"\u003cb\u003e","c":1}
+noHTMLEscape: {"a":"<b>"}
+indent: {
+  "a": 1
+}
+prefix: [
+//  1
+//]
+`)
+}
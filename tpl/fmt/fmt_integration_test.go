@@ -0,0 +1,47 @@
+package fmt_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"strings"
+	"testing"
+)
+
+func TestFmt(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+ignoreErrors = ["my-err"]
+-- layouts/index.html --
+F:{{ print "a" 1 }}|{{ printf "%03d" 7 }}|{{ println "x" }}
+W:{{ warnf "a warning %d" 1 }}{{ warnf "a warning %d" 1 }}
+E:{{ erroridf "my-err" "ignored error" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "F:a1|007|x", "W:", "E:")
+	b.AssertLogContains("a warning 1")
+	b.AssertLogNotContains("ignored error")
+}
+
+func TestFmtErrorfFailsBuild(t *testing.T) {
+	for _, tmpl := range []string{
+		`{{ errorf "failed: %s" "boom" }}`,
+		`{{ erroridf "other-err" "failed: %s" "boom" }}`,
+	} {
+		files := `
+-- config.toml --
+baseURL = "https://example.org/"
+ignoreErrors = ["my-err"]
+-- layouts/index.html --
+` + tmpl + `
+`
+
+		b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+		err := b.BuildE()
+		if err == nil || !strings.Contains(err.Error(), "logged 1 error(s)") {
+			t.Fatalf("%s: expected a logged error to fail the build, got %v", tmpl, err)
+		}
+		b.AssertLogContains("failed: boom")
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hash provides non-cryptographic hash functions for template use.
+package hash
+
+import (
+	"hash/fnv"
+
+	"github.com/spf13/cast"
+)
+
+// New returns a new instance of the hash-namespaced template functions.
+func New() *Namespace {
+	return &Namespace{}
+}
+
+// Namespace provides template functions for the "hash" namespace.
+type Namespace struct{}
+
+// FNV32a hashes s using the 32-bit FNV-1a algorithm.
+func (ns *Namespace) FNV32a(s any) (int, error) {
+	conv, err := cast.ToStringE(s)
+	if err != nil {
+		return 0, err
+	}
+	algorithm := fnv.New32a()
+	algorithm.Write([]byte(conv))
+	return int(algorithm.Sum32()), nil
+}
@@ -0,0 +1,19 @@
+package hash_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+fnv32a: {{ hash.FNV32a "Hugo Rocks!!" }}|{{ printf "%T" (hash.FNV32a "x") }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "fnv32a: 1515779328|int")
+}
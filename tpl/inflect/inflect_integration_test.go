@@ -0,0 +1,19 @@
+package inflect_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestInflect(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+IN:{{ humanize "my-first-post" }}|{{ humanize 103 }}|{{ pluralize "cat" }}|{{ singularize "dogs" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "IN:My first post|103rd|cats|dog")
+}
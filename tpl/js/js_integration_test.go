@@ -0,0 +1,107 @@
+package js_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- node_modules/mylib/package.json --
+{ "name": "mylib", "main": "index.js" }
+-- node_modules/mylib/index.js --
+export const lib = "from node_modules";
+-- assets/js/main.ts --
+import { greet } from "./util";
+import { shout } from "lib/shout";
+import { lib } from "mylib";
+import * as params from "@params";
+import React from "react";
+const n: number = 42;
+console.log(greet(params.name), shout("x"), lib, n, DEBUG, React);
+-- assets/js/util.ts --
+export function greet(name: string): string { return "hello " + name; }
+-- assets/lib/shout.js --
+export const shout = (s) => s.toUpperCase() + "!";
+-- assets/js/app.jsx --
+const App = () => <div className="a">Hi</div>;
+console.log(App);
+-- assets/js/inject.js --
+export const VERSION = "v1";
+-- assets/js/uses-inject.js --
+console.log(VERSION);
+-- layouts/index.html --
+{{ $opts := dict "params" (dict "name" "world") "defines" (dict "DEBUG" "false") "externals" (slice "react") "format" "esm" }}
+{{ $js := resources.Get "js/main.ts" | js.Build $opts }}JS {{ $js.RelPermalink }}|{{ $js.MediaType }}
+{{ $min := resources.Get "js/main.ts" | js.Build (merge $opts (dict "minify" true "targetPath" "js/bundle.min.js")) }}MIN {{ $min.RelPermalink }}
+{{ $inl := resources.Get "js/util.ts" | js.Build (dict "sourceMap" "inline" "targetPath" "js/inline.js") }}INLINE {{ strings.Contains $inl.Content "sourceMappingURL=data:" }}
+{{ $ext := resources.Get "js/main.ts" | js.Build (merge $opts (dict "sourceMap" "external" "targetPath" "js/ext.js")) }}EXT {{ $ext.RelPermalink }}
+{{ $jsx := resources.Get "js/app.jsx" | js.Build "js/app.js" }}JSX {{ $jsx.RelPermalink }}
+{{ $inj := resources.Get "js/uses-inject.js" | js.Build (dict "inject" (slice "js/inject.js")) }}INJECT {{ $inj.RelPermalink }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+JS /js/main.js|application/javascript
+MIN /js/bundle.min.js
+INLINE true
+EXT /js/ext.js
+JSX /js/app.js
+INJECT /js/uses-inject.js
+`)
+
+	b.AssertFileContent("js/main.js", `
+// ns-hugo:js/util.ts
+// ns-hugo:lib/shout.js
+// node_modules/mylib/index.js
+var lib = "from node_modules";
+var name = "world";
+// js/main.ts
+import React from "react";
+console.log(greet(name), shout("x"), lib, n, false, React);
+`)
+	b.AssertFileContent("js/bundle.min.js", `console.log(`, "! // ns-hugo")
+	b.AssertFileContent("js/app.js", `React.createElement("div", { className: "a" }, "Hi")`)
+	b.AssertFileContent("js/uses-inject.js", `var VERSION = "v1";`, `console.log(VERSION);`)
+
+	b.AssertFileContent("js/ext.js", "//# sourceMappingURL=ext.js.map")
+	b.AssertFileContent("js/ext.js.map", `"sources": ["ns-hugo:js/util.ts", "ns-hugo:lib/shout.js", "node_modules/mylib/index.js", "ns-params:@params", "js/main.ts"]`)
+
+	// No filenames from the build machine end up in the published files.
+	wd := filepath.ToSlash(b.Cfg.WorkingDir)
+	for _, filename := range []string{"js/main.js", "js/app.js", "js/ext.js", "js/ext.js.map"} {
+		if content := b.FileContent(filename); strings.Contains(content, wd) {
+			t.Errorf("%s contains the working dir %q", filename, wd)
+		}
+	}
+}
+
+func TestBuildError(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- assets/js/main.js --
+import { x } from "./bad";
+console.log(x);
+-- assets/js/bad.js --
+export let x = ;
+-- layouts/index.html --
+{{ (resources.Get "js/main.js" | js.Build).Content }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expect := filepath.Join(b.Cfg.WorkingDir, "assets", "js", "bad.js") + `:1:15": Unexpected ";"`
+	if !strings.Contains(err.Error(), expect) {
+		t.Fatalf("got error %q, want %q", err, expect)
+	}
+}
@@ -0,0 +1,41 @@
+package math_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestMath(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+M:{{ add 1 2 }}|{{ sub 5 2.5 }}|{{ mul 3 4 }}|{{ div 10 4 }}|{{ div 10.0 4 }}|{{ mod 7 3 }}|{{ modBool 6 3 }}
+M2:{{ math.Ceil 1.2 }}|{{ math.Floor 1.8 }}|{{ math.Round 1.5 }}|{{ math.Sqrt 16 }}|{{ pow 2 10 }}|{{ math.Log 1 }}|{{ math.Max 1 2 }}|{{ math.Min 1 2 }}
+SUM:{{ math.Sum 1 2 3 }}|{{ math.Sum (slice 1.5 2) 1 }}
+CNT:{{ math.Counter }}|{{ math.Counter }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+M:3|2.5|12|2|2.5|1|true
+M2:2|1|2|4|1024|0|2|1
+SUM:6|4.5
+CNT:1|2
+`)
+}
+
+func TestMathDivideByZero(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+{{ div 1 0 }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	if err := b.BuildE(); err == nil {
+		t.Fatal("expected division by zero to fail the build")
+	}
+}
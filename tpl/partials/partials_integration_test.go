@@ -0,0 +1,103 @@
+package partials_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"strings"
+	"testing"
+)
+
+func TestPartials(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/blog/post.md --
+---
+title: "Post Title"
+---
+-- layouts/index.html --
+HOME
+P:{{ partial "hello" . }}|{{ partial "hello.html" . }}
+R:{{ $n := partial "num.html" 3 }}{{ $n }}|{{ printf "%T" $n }}|{{ partial "falsy.html" false }}|{{ partial "dict.html" (dict "a" 1) }}
+I:{{ partial "inline" "x" }}
+T:{{ partial "text.txt" . }}
+{{ define "partials/inline" }}INLINE {{ . }}{{ end }}
+-- layouts/partials/hello.html --
+Hello {{ .Site.Title }} <b>x</b>
+-- layouts/partials/num.html --
+{{ $x := mul . 2 }}
+{{ return $x }}
+-- layouts/partials/falsy.html --
+{{ return (printf "%v" .) }}
+-- layouts/partials/dict.html --
+{{ return .a }}
+-- layouts/partials/text.txt --
+TEXT
+-- layouts/_default/single.html --
+SINGLE {{ .Title }}|{{ partial "rec.html" 0 }}
+-- layouts/partials/rec.html --
+{{ if lt . 5 }}R{{ . }}{{ partial "rec.html" (add . 1) }}{{ end }}
+-- layouts/_default/list.html --
+LIST {{ .Title }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+P:Hello  <b>x</b>|Hello  <b>x</b>
+R:6|int64|false|1
+I:INLINE x
+T:TEXT
+`)
+	b.AssertFileContent("blog/post/index.html", "SINGLE Post Title|R0R1R2R3R4")
+}
+
+func TestPartialCached(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+templateMetrics = true
+-- layouts/index.html --
+{{ $a1 := partialCached "counter.html" . "a" }}{{ $a2 := partialCached "counter.html" . "a" }}{{ $b := partialCached "counter.html" . "b" }}
+SAME:{{ eq $a1 $a2 }}|DIFF:{{ ne $a1 $b }}
+-- layouts/partials/counter.html --
+{{ now.UnixNano }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "SAME:true|DIFF:true")
+	b.AssertLogContains("Template Metrics")
+	b.AssertLogContains("partials/counter.html")
+}
+
+func TestPartialRecursionLimit(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+{{ partial "loop.html" . }}
+-- layouts/partials/loop.html --
+{{ partial "loop.html" . }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil || !strings.Contains(err.Error(), `maximum call depth (100) exceeded`) {
+		t.Fatalf("expected partial recursion error, got %v", err)
+	}
+}
+
+func TestPartialNotFound(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+{{ partial "nope.html" . }}
+`
+
+	b := hugolib.NewIntegrationTestBuilder(hugolib.IntegrationTestConfig{T: t, TxtarString: files})
+	err := b.BuildE()
+	if err == nil || !strings.Contains(err.Error(), `partial "nope.html" not found`) {
+		t.Fatalf("expected partial not found error, got %v", err)
+	}
+}
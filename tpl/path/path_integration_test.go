@@ -0,0 +1,33 @@
+package path_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+Ext: {{ path.Ext "a/b/c.news.md" }}
+Dir: {{ path.Dir "a/b/c.md" }}|{{ path.Dir "c.md" }}
+Base: {{ path.Base "a/b/c.md" }}|{{ path.Base "a/b/" }}
+BaseName: {{ path.BaseName "a/b/c.md" }}
+Split: {{ path.Split "a/b/c.md" }}
+Join: {{ path.Join "a" "b" "../c" "d.md" }}|{{ path.Join (slice "x" "y") "z" }}
+Clean: {{ path.Clean "a//b/./c/../d" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+Ext: .md
+Dir: a/b|.
+Base: c.md|b
+BaseName: c
+Split: a/b/|c.md
+Join: a/c/d.md|x/y/z
+Clean: a/b/d
+`)
+}
@@ -0,0 +1,90 @@
+package resources_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestResources(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+title = "My Site"
+-- assets/css/a.css --
+body {
+    color:   red;
+}
+-- assets/css/b.css --
+p {  margin: 0px ; }
+-- assets/js/main.js --
+var   x = 1;
+-- assets/js/other.js --
+var y = 2
+-- assets/tpl/vars.css --
+:root { --title: "{{ .Site.Title }}"; }
+-- layouts/index.html --
+{{ $a := resources.Get "css/a.css" }}GET {{ $a.RelPermalink }}|{{ $a.MediaType }}|{{ $a.ResourceType }}
+{{ $min := $a | minify }}MIN {{ $min.RelPermalink }}|{{ $min.Content }}
+{{ $fp := $min | fingerprint }}FP {{ $fp.RelPermalink }}|{{ $fp.Data.Integrity }}
+{{ $fp5 := resources.Fingerprint "sha512" $a }}FP512 {{ $fp5.Data.Integrity }}
+{{ $css := slice $a (resources.Get "css/b.css") | resources.Concat "css/bundle.css" }}CONCAT {{ $css.RelPermalink }}
+{{ $js := resources.Match "js/*.js" | resources.Concat "js/all.js" | minify }}JS {{ $js.RelPermalink }}|{{ $js.Content }}
+MATCH {{ range resources.Match "css/*.css" }}{{ .Name }},{{ end }}
+GM {{ (resources.GetMatch "**.js").Name }}
+{{ $s := resources.FromString "hello.txt" "Hello World" }}FS {{ $s.RelPermalink }}|{{ $s.Content }}
+{{ $t := resources.Get "tpl/vars.css" | resources.ExecuteAsTemplate "css/vars.css" . }}EAT {{ $t.RelPermalink }}|{{ $t.Content | safeHTML }}
+{{ $c := resources.Copy "copied/a.css" $a }}COPY {{ $c.RelPermalink }}
+{{ $a2 := resources.Get "css/a.css" | minify }}SAME {{ eq $a2.RelPermalink $min.RelPermalink }}
+BYTYPE {{ len (resources.ByType "text") }}
+{{ with resources.Get "nope.css" }}NOPE{{ else }}NIL{{ end }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+GET /css/a.css|text/css|text
+MIN /css/a.min.css|body{color:red}
+FP /css/a.min.15c42ab7768d955ec0667195e339104557827893b16cc3e7412c76e7c2fcd371.css|sha256-FcQqt3aNlV7AZnGV4zkQRVeCeJOxbMPnQSx258L803E=
+FP512 sha512-
+CONCAT /css/bundle.css
+JS /js/all.min.js|var x=1,y=2
+MATCH css/a.css,css/b.css,
+GM js/main.js
+FS /hello.txt|Hello World
+EAT /css/vars.css|:root { --title: "My Site"; }
+COPY /copied/a.css
+SAME true
+BYTYPE 3
+NIL
+`)
+
+	// Resources are published on first permalink access.
+	b.AssertFileContent("css/a.min.css", "body{color:red}")
+	b.AssertFileContent("css/bundle.css", "color:   red;", "p {  margin: 0px ; }")
+	b.AssertFileContent("js/all.min.js", "var x=1,y=2")
+	b.AssertFileContent("copied/a.css", "color:   red;")
+	b.AssertFileContent("hello.txt", "Hello World")
+	b.AssertFileExists("css/b.css", false)
+}
+
+func TestResourcesMultilingual(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+-- assets/css/a.css --
+body { color: red; }
+-- layouts/index.html --
+{{ .Lang }}|{{ (resources.Get "css/a.css" | minify).RelPermalink }}
+`
+
+	b := hugolib.Test(t, files)
+
+	// Global assets are shared between languages.
+	b.AssertFileContent("index.html", "en|/css/a.min.css")
+	b.AssertFileContent("fr/index.html", "fr|/css/a.min.css")
+	b.AssertFileContent("css/a.min.css", "body{color:red}")
+}
@@ -0,0 +1,34 @@
+package safe_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestSafe(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+{{ $html := "<b>x</b>" }}{{ $url := "javascript:alert(1)" }}
+html: {{ $html }}|{{ $html | safeHTML }}
+attr: <p {{ "data-x=\"1\"" | safeHTMLAttr }}>|<p {{ "data-x=\"1\"" }}>
+url: <a href="{{ $url }}">|<a href="{{ $url | safeURL }}">
+css: <p style="{{ "color: red" | safeCSS }}">|<p style="{{ "expression(x)" }}">
+js: <script>var a = {{ "x = 1" | safeJS }};</script>
+jsstr: <script>var s = "{{ "it's" | safeJSStr }}";</script>
+sanitize: {{ safe.SanitizeURL "Foo Bar/Baz" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+html: &lt;b&gt;x&lt;/b&gt;|<b>x</b>
+attr: <p data-x="1">|<p ZgotmplZ>
+url: <a href="#ZgotmplZ">|<a href="javascript:alert%281%29">
+css: <p style="color: red">|<p style="ZgotmplZ">
+js: <script>var a = x = 1;</script>
+jsstr: <script>var s = "it\u0027s";</script>
+sanitize: /Foo%20Bar/Baz
+`)
+}
@@ -0,0 +1,59 @@
+package strings_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestStrings(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- layouts/index.html --
+UP:{{ upper "hugo" }}|{{ lower "HuGo" }}
+TI:{{ title "the lord of the rings" }}
+TR:{{ truncate 10 "This is a very long sentence" }}
+TH:{{ truncate 8 ("<em>Hello</em> world again" | safeHTML) }}|{{ truncate 8 "***" ("<b>Hello</b> world" | safeHTML) }}|{{ truncate 8 "<em>x</em>" }}
+RE:{{ replaceRE "^https?://([^/]+).*" "$1" "https://gohugo.io/docs" }}|{{ replace "Batman" "Bat" "Super" }}
+FR:{{ findRE "[G|g]o" "Hugo is Go" }}
+FS:{{ findRESubmatch "a(x*)b" "-ab-axxb-" }}
+FS1:{{ findRESubmatch "a(x*)b" "-ab-axxb-" 1 }}
+CW:{{ countwords "one two three" }}|{{ countrunes "a b c" }}
+SP:{{ split "a,b,c" "," }}
+TM:{{ trim "--x--" "-" }}|{{ strings.TrimLeft "ab" "abc" }}|{{ strings.TrimPrefix "pre" "prefix" }}|{{ strings.TrimSuffix "fix" "prefix" }}
+SS:{{ substr "BatMan" 0 -3 }}|{{ slicestr "BatMan" 3 }}|{{ strings.Repeat 3 "ab" }}
+CH:{{ chomp "x\n" }}|{{ strings.FirstUpper "foo" }}|{{ strings.Contains "abc" "b" }}|{{ strings.ContainsAny "abc" "xc" }}|{{ hasPrefix "abc" "ab" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+UP:HUGO|hugo
+TI:The Lord of the Rings
+TR:This is a …
+TH:<em>Hello …</em>|<b>Hello***</b>|&lt;em&gt;x&lt;/e …
+RE:gohugo.io|Superman
+FR:[go Go]
+FS:[[ab ] [axxb xx]]
+FS1:[[ab ]]
+CW:3|3
+SP:[a b c]
+TM:x|c|fix|pre
+SS:Bat|Man|ababab
+CH:x|Foo|true|true|true
+`)
+}
+
+func TestStringsTitleCaseStyle(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+titleCaseStyle = "go"
+-- layouts/index.html --
+TI:{{ title "the lord of the rings" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "TI:The Lord Of The Rings")
+}
@@ -39,6 +39,7 @@ type Template interface {
 // TemplateFinder finds templates.
 type TemplateFinder interface {
 	TemplateLookup
+	TemplateLookupVariant
 }
 
 // TemplateFuncGetter allows to find a template func by name.
@@ -57,6 +58,12 @@ type TemplateParser interface {
 	Parse(name, tpl string) (Template, error)
 }
 
+// TemplatesProvider as implemented by deps.Deps.
+type TemplatesProvider interface {
+	Tmpl() TemplateHandler
+	TextTmpl() TemplateParseFinder
+}
+
 // TemplateVariants describes the possible variants of a template.
 // All of these may be empty.
 type TemplateVariants struct {
@@ -68,6 +75,20 @@ type TemplateLookup interface {
 	Lookup(name string) (Template, bool)
 }
 
+type TemplateLookupVariant interface {
+	// TODO(bep) this currently only works for shortcodes.
+	// We may unify and expand this variant pattern to the
+	// other templates, but we need this now for the shortcodes to
+	// quickly determine if a shortcode has a template for a given
+	// output format.
+	// It returns the template, if it was found or not and if there are
+	// alternative representations (output format, language).
+	// We are currently only interested in output formats, so we should improve
+	// this for speed.
+	LookupVariant(name string, variants TemplateVariants) (Template, bool, bool)
+	LookupVariants(name string) []Template
+}
+
 const hugoNewLinePlaceholder = "___hugonl_"
 
 var (
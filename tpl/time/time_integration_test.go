@@ -0,0 +1,93 @@
+package time_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestTime(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "de"
+timeZone = "Europe/Oslo"
+-- layouts/index.html --
+DF:{{ dateFormat "Monday, January 2, 2006" "2021-03-15" }}|{{ dateFormat ":date_long" "2021-03-15" }}|{{ dateFormat ":time_short" "2021-03-15T14:05:00" }}
+TF:{{ time.Format "Jan 2006" "2021-10-01" }}
+AT:{{ (time "2021-03-15").Year }}|{{ time.AsTime "2021-03-15T10:00:00" "America/New_York" }}|{{ (time.AsTime "2021-03-15T10:00:00").Location }}
+DU:{{ duration "minute" 90 }}|{{ time.ParseDuration "1h12m" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+DF:Montag, März 15, 2021|15. März 2021|14:05
+TF:Okt. 2021
+AT:2021|2021-03-15 10:00:00 -0400 EDT|Europe/Oslo
+DU:1h30m0s|1h12m0s
+`)
+}
+
+func TestTimeMultilingual(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "en"
+[languages.en]
+weight = 1
+timeZone = "America/New_York"
+[languages.fr]
+weight = 2
+timeZone = "Europe/Paris"
+[languages.de]
+weight = 3
+-- content/blog/a.md --
+---
+title: "A"
+date: 2021-03-15T10:30:00Z
+---
+-- content/blog/a.fr.md --
+---
+title: "A"
+date: 2021-03-15T10:30:00Z
+---
+-- layouts/index.html --
+{{ .Lang }}|{{ dateFormat "Monday, January 2, 2006" "2021-03-15" }}|{{ dateFormat ":date_long" "2021-03-15" }}|{{ time.Format "Jan 2006" "2021-10-01" }}|{{ (time.AsTime "2021-03-15T10:00:00").Location }}|G:{{ range .Site.RegularPages.GroupByDate "January 2006" }}{{ .Key }},{{ end }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "en|Monday, March 15, 2021|March 15, 2021|Oct 2021|America/New_York|G:March 2021,")
+	b.AssertFileContent("fr/index.html", "fr|lundi, mars 15, 2021|15 mars 2021|oct. 2021|Europe/Paris|G:mars 2021,")
+	b.AssertFileContent("de/index.html", "de|Montag, März 15, 2021|15. März 2021|Okt. 2021|UTC|G:")
+}
+
+func TestTimeFormatPageDates(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+defaultContentLanguage = "de"
+[languages.de]
+weight = 1
+[languages.en]
+weight = 2
+-- content/a.md --
+---
+title: "A"
+date: 2021-03-04T10:30:00Z
+lastmod: 2021-10-06T10:30:00Z
+---
+-- content/a.en.md --
+---
+title: "A"
+date: 2021-03-04T10:30:00Z
+---
+-- layouts/_default/single.html --
+{{ .Lang }}|{{ .Date.Format "2. January 2006" }}|{{ .Date.Format ":date_long" }}|{{ .Date.Format "Mon Jan 2" }}|{{ .Lastmod.Format "January" }}|{{ (time "2021-12-01").Format "January" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("a/index.html", "de|4. März 2021|4. März 2021|Do. März 4|Oktober|Dezember")
+	b.AssertFileContent("en/a/index.html", "en|4. March 2021|March 4, 2021|Thu Mar 4|March|December")
+}
@@ -23,8 +23,10 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -66,6 +68,19 @@ type templateHandler struct {
 	main          *templateNamespace
 	readyInit     sync.Once
 	layoutHandler *output.LayoutHandler
+
+	// shortcodes maps shortcode name to template variants
+	// (language, output format etc.) of that shortcode.
+	shortcodes map[string]*shortcodeTemplates
+
+	// transformNotFound keeps track of templates referenced, but not found
+	// when the transformers ran, e.g. inline partials defined later.
+	transformNotFound map[string]*templateState
+
+	// identityNotFound holds the identities waiting for a template to be
+	// added, e.g. a partial referenced before it was parsed.
+	identityNotFound map[string][]identity.Manager
+
 	*deps.Deps
 }
 
@@ -87,7 +102,11 @@ func newTemplateExec(d *deps.Deps) (*templateExec, error) {
 	log.Process("newTemplateNamespace", "with funcMap")
 	log.Process("NewLayoutHandler", "to process layout request")
 	h := &templateHandler{
-		main: newTemplateNamespace(funcMap),
+		main:       newTemplateNamespace(funcMap),
+		shortcodes: make(map[string]*shortcodeTemplates),
+
+		transformNotFound: make(map[string]*templateState),
+		identityNotFound:  make(map[string][]identity.Manager),
 
 		Deps:          d,
 		layoutHandler: output.NewLayoutHandler(),
@@ -130,6 +149,7 @@ func newTemplateNamespace(funcs map[string]any) *templateNamespace {
 //go:embed embedded/templates/*
 //go:embed embedded/templates/_default/*
 //go:embed embedded/templates/_server/*
+//go:embed embedded/templates/shortcodes/*
 var embededTemplatesFs embed.FS
 
 func (t *templateHandler) loadEmbedded() error {
@@ -178,6 +198,67 @@ func (t *templateHandler) Lookup(name string) (tpl.Template, bool) {
 	return nil, false
 }
 
+// This currently only applies to shortcodes and what we get here is the
+// shortcode name.
+func (t *templateHandler) LookupVariant(name string, variants tpl.TemplateVariants) (tpl.Template, bool, bool) {
+	name = templateBaseName(templateShortcode, name)
+	s, found := t.shortcodes[name]
+	if !found {
+		return nil, false, false
+	}
+
+	sv, found := s.fromVariants(variants)
+	if !found {
+		return nil, false, false
+	}
+
+	more := len(s.variants) > 1
+
+	return sv.ts, true, more
+}
+
+// LookupVariants returns all variants of name, nil if none found.
+func (t *templateHandler) LookupVariants(name string) []tpl.Template {
+	name = templateBaseName(templateShortcode, name)
+	s, found := t.shortcodes[name]
+	if !found {
+		return nil
+	}
+
+	variants := make([]tpl.Template, len(s.variants))
+	for i := 0; i < len(variants); i++ {
+		variants[i] = s.variants[i].ts
+	}
+
+	return variants
+}
+
+func (t *templateHandler) addShortcodeVariant(ts *templateState) {
+	name := ts.Name()
+	base := templateBaseName(templateShortcode, name)
+
+	shortcodename, variants := templateNameAndVariants(base)
+
+	templs, found := t.shortcodes[shortcodename]
+	if !found {
+		templs = &shortcodeTemplates{}
+		t.shortcodes[shortcodename] = templs
+	}
+
+	sv := shortcodeVariant{variants: variants, ts: ts}
+
+	i := templs.indexOf(variants)
+
+	if i != -1 {
+		// Only replace if it's an override of an internal template.
+		if !isInternal(name) {
+			templs.variants[i] = sv
+		}
+	} else {
+		templs.variants = append(templs.variants, sv)
+	}
+}
+
 func (t *templateNamespace) Lookup(name string) (tpl.Template, bool) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -266,6 +347,10 @@ func newTemplateState(templ tpl.Template, info templateInfo) *templateState {
 	}
 }
 
+func (t *templateState) ParseInfo() tpl.ParseInfo {
+	return t.parseInfo
+}
+
 func newIdentity(name string) identity.Manager {
 	return identity.NewManager(identity.NewPathIdentity(files.ComponentFolderLayouts, name))
 }
@@ -276,6 +361,15 @@ func (t *templateHandler) applyTemplateTransformers(ns *templateNamespace, ts *t
 		return nil, err
 	}
 
+	for k := range c.templateNotFound {
+		t.transformNotFound[k] = ts
+		t.identityNotFound[k] = append(t.identityNotFound[k], c.t)
+	}
+
+	for k := range c.identityNotFound {
+		t.identityNotFound[k] = append(t.identityNotFound[k], c.t)
+	}
+
 	return c, err
 }
 
@@ -303,6 +397,17 @@ func isText(templ tpl.Template) bool {
 	return isText
 }
 
+// resolves _internal/shortcodes/param.html => param.html etc.
+func templateBaseName(typ templateType, name string) string {
+	name = strings.TrimPrefix(name, internalPathPrefix)
+	switch typ {
+	case templateShortcode:
+		return strings.TrimPrefix(name, shortcodesPathPrefix)
+	default:
+		panic("not implemented")
+	}
+}
+
 func unwrap(templ tpl.Template) tpl.Template {
 	if ts, ok := templ.(*templateState); ok {
 		return ts.Template
@@ -434,6 +539,9 @@ func (t *templateExec) ExecuteWithContext(ctx context.Context, templ tpl.Templat
 		rlocker.RLock()
 		defer rlocker.RUnlock()
 	}
+	if t.Metrics != nil {
+		defer t.Metrics.MeasureSince(templ.Name(), time.Now())
+	}
 
 	execErr := t.executor.ExecuteWithContext(ctx, templ, wr, data)
 	if execErr != nil {
@@ -567,7 +675,25 @@ func (t *templateHandler) extractPartials(templ tpl.Template) error {
 			continue
 		}
 
-		panic("extract partials not supported yet")
+		ts := newTemplateState(templ, templateInfo{name: templ.Name()})
+		ts.typ = templatePartial
+
+		t.main.mu.RLock()
+		_, found := t.main.templates[templ.Name()]
+		t.main.mu.RUnlock()
+
+		if !found {
+			t.main.mu.Lock()
+			// This is a template defined inline.
+			_, err := applyTemplateTransformers(ts, t.main.newTemplateLookup(ts))
+			if err != nil {
+				t.main.mu.Unlock()
+				return err
+			}
+			t.main.templates[templ.Name()] = ts
+			t.main.mu.Unlock()
+
+		}
 	}
 
 	return nil
@@ -635,6 +761,10 @@ func (t *templateHandler) postTransform() error {
 	defineCheckedText := false
 
 	for _, v := range t.main.templates {
+		if v.typ == templateShortcode {
+			t.addShortcodeVariant(v)
+		}
+
 		if defineCheckedHTML && defineCheckedText {
 			continue
 		}
@@ -657,6 +787,44 @@ func (t *templateHandler) postTransform() error {
 		}
 	}
 
+	for name, source := range t.transformNotFound {
+		lookup := t.main.newTemplateLookup(source)
+		templ := lookup(name)
+		if templ != nil {
+			_, err := applyTemplateTransformers(templ, lookup)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for k, v := range t.identityNotFound {
+		ts := t.findTemplate(k)
+		if ts != nil {
+			for _, im := range v {
+				im.Add(ts)
+			}
+		}
+	}
+
+	for _, v := range t.shortcodes {
+		sort.Slice(v.variants, func(i, j int) bool {
+			v1, v2 := v.variants[i], v.variants[j]
+			name1, name2 := v1.ts.Name(), v2.ts.Name()
+			isHTMl1, isHTML2 := strings.HasSuffix(name1, "html"), strings.HasSuffix(name2, "html")
+
+			// There will be a weighted selection later, but make
+			// sure these are sorted to get a stable selection for
+			// output formats missing specific templates.
+			// Prefer HTML.
+			if isHTMl1 || isHTML2 && !(isHTMl1 && isHTML2) {
+				return isHTMl1
+			}
+
+			return name1 < name2
+		})
+	}
+
 	return nil
 }
 
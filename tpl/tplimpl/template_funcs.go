@@ -3,20 +3,41 @@ package tplimpl
 import (
 	"context"
 	"github.com/sunwei/hugo-playground/common/hreflect"
+	"github.com/sunwei/hugo-playground/common/htime"
 	"github.com/sunwei/hugo-playground/common/maps"
 	"github.com/sunwei/hugo-playground/deps"
+	"github.com/sunwei/hugo-playground/langs"
 	"github.com/sunwei/hugo-playground/log"
 	"github.com/sunwei/hugo-playground/tpl/internal"
 	template "github.com/sunwei/hugo-playground/tpl/internal/go_templates/htmltemplate"
 	"github.com/sunwei/hugo-playground/tpl/internal/go_templates/texttemplate"
 	"reflect"
 	"strings"
+	"time"
 
 	// Init the namespaces
+	_ "github.com/sunwei/hugo-playground/tpl/cast"
+	_ "github.com/sunwei/hugo-playground/tpl/collections"
 	_ "github.com/sunwei/hugo-playground/tpl/compare"
+	_ "github.com/sunwei/hugo-playground/tpl/crypto"
+	_ "github.com/sunwei/hugo-playground/tpl/css"
 	_ "github.com/sunwei/hugo-playground/tpl/diagrams"
+	_ "github.com/sunwei/hugo-playground/tpl/encoding"
+	_ "github.com/sunwei/hugo-playground/tpl/fmt"
+	_ "github.com/sunwei/hugo-playground/tpl/hash"
+	_ "github.com/sunwei/hugo-playground/tpl/images"
+	_ "github.com/sunwei/hugo-playground/tpl/inflect"
+	_ "github.com/sunwei/hugo-playground/tpl/js"
+	_ "github.com/sunwei/hugo-playground/tpl/math"
 	_ "github.com/sunwei/hugo-playground/tpl/os"
+	_ "github.com/sunwei/hugo-playground/tpl/partials"
+	_ "github.com/sunwei/hugo-playground/tpl/path"
+	_ "github.com/sunwei/hugo-playground/tpl/resources"
+	_ "github.com/sunwei/hugo-playground/tpl/safe"
+	_ "github.com/sunwei/hugo-playground/tpl/strings"
+	_ "github.com/sunwei/hugo-playground/tpl/time"
 	_ "github.com/sunwei/hugo-playground/tpl/transform"
+	_ "github.com/sunwei/hugo-playground/tpl/urls"
 )
 
 func newTemplateExecuter(d *deps.Deps) (texttemplate.Executer, map[string]reflect.Value) {
@@ -48,7 +69,8 @@ func newTemplateExecuter(d *deps.Deps) (texttemplate.Executer, map[string]reflec
 	}
 
 	exeHelper := &templateExecHelper{
-		funcs: funcsv,
+		funcs:         funcsv,
+		timeFormatter: langs.GetTimeFormatter(d.Language),
 	}
 
 	return texttemplate.NewExecuter(
@@ -80,12 +102,14 @@ func createFuncMap(d *deps.Deps) map[string]any {
 }
 
 type templateExecHelper struct {
-	funcs map[string]reflect.Value
+	funcs         map[string]reflect.Value
+	timeFormatter htime.TimeFormatter
 }
 
 var (
 	zero             reflect.Value
 	contextInterface = reflect.TypeOf((*context.Context)(nil)).Elem()
+	timeType         = reflect.TypeOf(time.Time{})
 )
 
 func (t *templateExecHelper) GetFunc(ctx context.Context, tmpl texttemplate.Preparer, name string) (fn reflect.Value, firstArg reflect.Value, found bool) {
@@ -126,6 +150,16 @@ func (t *templateExecHelper) GetMapValue(ctx context.Context, tmpl texttemplate.
 
 func (t *templateExecHelper) GetMethod(ctx context.Context, tmpl texttemplate.Preparer, receiver reflect.Value,
 	name string) (method reflect.Value, firstArg reflect.Value) {
+	if name == "Format" {
+		if tv := reflect.Indirect(receiver); tv.Type() == timeType {
+			// Format dates, e.g. .Date.Format, in the current language, as time.Format does.
+			tm := tv.Interface().(time.Time)
+			return reflect.ValueOf(func(layout string) string {
+				return t.timeFormatter.Format(tm, layout)
+			}), zero
+		}
+	}
+
 	fn := hreflect.GetMethodByName(receiver, name)
 	if !fn.IsValid() {
 		return zero, zero
@@ -0,0 +1,30 @@
+package tplimpl_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestEmbeddedSchemaTemplate(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/"
+-- content/a.md --
+---
+title: "A"
+date: 2021-03-04T10:30:00Z
+lastmod: 2021-03-05T10:30:00Z
+---
+-- layouts/_default/single.html --
+{{ template "_internal/schema.html" . }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("a/index.html", `
+<meta itemprop="name" content="A">
+<meta itemprop="datePublished" content="2021-03-04T10:30:00&#43;00:00" />
+<meta itemprop="dateModified" content="2021-03-05T10:30:00&#43;00:00" />
+! ZgotmplZ
+`)
+}
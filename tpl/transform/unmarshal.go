@@ -16,20 +16,15 @@ package transform
 import (
 	"fmt"
 	"io/ioutil"
-	"strings"
 
 	"github.com/sunwei/hugo-playground/resources/resource"
 
 	"github.com/sunwei/hugo-playground/common/types"
 
-	"github.com/mitchellh/mapstructure"
-
 	"errors"
 
 	"github.com/sunwei/hugo-playground/helpers"
 	"github.com/sunwei/hugo-playground/parser/metadecoders"
-
-	"github.com/spf13/cast"
 )
 
 // Unmarshal unmarshals the data given, which can be either a string, json.RawMessage
@@ -54,7 +49,7 @@ func (ns *Namespace) Unmarshal(args ...any) (any, error) {
 		var err error
 
 		data = args[1]
-		decoder, err = decodeDecoder(m)
+		decoder, err = metadecoders.DecodeDecoder(m)
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode options: %w", err)
 		}
@@ -112,59 +107,3 @@ func (ns *Namespace) Unmarshal(args ...any) (any, error) {
 		return decoder.Unmarshal([]byte(dataStr), f)
 	})
 }
-
-func decodeDecoder(m map[string]any) (metadecoders.Decoder, error) {
-	opts := metadecoders.Default
-
-	if m == nil {
-		return opts, nil
-	}
-
-	// mapstructure does not support string to rune conversion, so do that manually.
-	// See https://github.com/mitchellh/mapstructure/issues/151
-	for k, v := range m {
-		if strings.EqualFold(k, "Delimiter") {
-			r, err := stringToRune(v)
-			if err != nil {
-				return opts, err
-			}
-			opts.Delimiter = r
-			delete(m, k)
-
-		} else if strings.EqualFold(k, "Comment") {
-			r, err := stringToRune(v)
-			if err != nil {
-				return opts, err
-			}
-			opts.Comment = r
-			delete(m, k)
-		}
-	}
-
-	err := mapstructure.WeakDecode(m, &opts)
-
-	return opts, err
-}
-
-func stringToRune(v any) (rune, error) {
-	s, err := cast.ToStringE(v)
-	if err != nil {
-		return 0, err
-	}
-
-	if len(s) == 0 {
-		return 0, nil
-	}
-
-	var r rune
-
-	for i, rr := range s {
-		if i == 0 {
-			r = rr
-		} else {
-			return 0, fmt.Errorf("invalid character: %q", v)
-		}
-	}
-
-	return r, nil
-}
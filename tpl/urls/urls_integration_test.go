@@ -0,0 +1,52 @@
+package urls_test
+
+import (
+	"github.com/sunwei/hugo-playground/hugolib"
+	"testing"
+)
+
+func TestURLs(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/docs/"
+-- layouts/index.html --
+absURL: {{ absURL "foo" }}|{{ absURL "/foo" }}|{{ absURL "https://other.org/x" }}
+relURL: {{ relURL "foo" }}|{{ relURL "/foo" }}
+absLangURL: {{ absLangURL "foo" }}
+relLangURL: {{ relLangURL "foo" }}
+urlize: {{ urlize "Hello World" }}
+anchorize: {{ anchorize "Hello, World!" }}
+parse: {{ with urls.Parse "https://a.org/b?c=d#e" }}{{ .Host }}|{{ .Path }}|{{ .RawQuery }}|{{ .Fragment }}{{ end }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", `
+absURL: https://example.org/docs/foo|https://example.org/foo|https://other.org/x
+relURL: /docs/foo|/foo
+absLangURL: https://example.org/docs/foo
+relLangURL: /docs/foo
+urlize: hello-world
+anchorize: hello-world
+parse: a.org|/b|c=d|e
+`)
+}
+
+func TestURLsMultilingual(t *testing.T) {
+	files := `
+-- config.toml --
+baseURL = "https://example.org/docs/"
+defaultContentLanguage = "en"
+[languages.en]
+weight = 1
+[languages.fr]
+weight = 2
+-- layouts/index.html --
+{{ .Lang }}|abs:{{ absLangURL "foo" }}|rel:{{ relLangURL "foo" }}|root:{{ relLangURL "/foo" }}|ext:{{ absLangURL "https://other.org/x" }}|url:{{ absURL "foo" }}
+`
+
+	b := hugolib.Test(t, files)
+
+	b.AssertFileContent("index.html", "en|abs:https://example.org/docs/foo|rel:/docs/foo|root:/foo|ext:https://other.org/x|url:https://example.org/docs/foo")
+	b.AssertFileContent("fr/index.html", "fr|abs:https://example.org/docs/fr/foo|rel:/docs/fr/foo|root:/fr/foo|ext:https://other.org/x|url:https://example.org/docs/foo")
+}